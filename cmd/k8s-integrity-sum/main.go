@@ -10,18 +10,22 @@ import (
 	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
 
+	"github.com/ScienceSoft-Inc/integrity-sum/internal/api"
 	_ "github.com/ScienceSoft-Inc/integrity-sum/internal/configs"
 	_ "github.com/ScienceSoft-Inc/integrity-sum/internal/ffi/bee2"
 	"github.com/ScienceSoft-Inc/integrity-sum/internal/integritymonitor"
 	"github.com/ScienceSoft-Inc/integrity-sum/internal/logger"
 	"github.com/ScienceSoft-Inc/integrity-sum/internal/utils/graceful"
+	"github.com/ScienceSoft-Inc/integrity-sum/internal/utils/process"
 	"github.com/ScienceSoft-Inc/integrity-sum/pkg/alerts"
 	"github.com/ScienceSoft-Inc/integrity-sum/pkg/alerts/splunk"
 	syslogclient "github.com/ScienceSoft-Inc/integrity-sum/pkg/alerts/syslog"
 	"github.com/ScienceSoft-Inc/integrity-sum/pkg/common"
 	"github.com/ScienceSoft-Inc/integrity-sum/pkg/health"
 	"github.com/ScienceSoft-Inc/integrity-sum/pkg/k8s"
+	"github.com/ScienceSoft-Inc/integrity-sum/pkg/metrics"
 	"github.com/ScienceSoft-Inc/integrity-sum/pkg/minio"
+	"github.com/ScienceSoft-Inc/integrity-sum/pkg/tracing"
 )
 
 var ImageVersion string
@@ -34,9 +38,28 @@ func main() {
 	log := logger.Init(viper.GetString("verbose"))
 	log.Infof("version: %s", ImageVersion)
 
+	shutdownTracing, err := tracing.Init(context.Background(), tracing.Config{
+		Endpoint:       viper.GetString("tracing-endpoint"),
+		Insecure:       viper.GetBool("tracing-insecure"),
+		SampleRatio:    viper.GetFloat64("tracing-sample-ratio"),
+		ServiceName:    common.AppId,
+		ServiceVersion: ImageVersion,
+	})
+	if err != nil {
+		log.WithError(err).Fatal("failed init tracing")
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(ctx); err != nil {
+			log.WithError(err).Error("failed flush traces")
+		}
+	}()
+	log.AddHook(tracing.LogHook{})
+
 	// Set app health status to healthy
 	h := health.New(fmt.Sprintf("/tmp/%s", common.AppId))
-	err := h.Set()
+	err = h.Set()
 	if err != nil {
 		log.Fatalf("cannot create health file")
 	}
@@ -44,19 +67,19 @@ func main() {
 
 	_, err = minio.NewStorage(log)
 	if err != nil {
-		log.Fatalf("failed connect to minio storage: %w", err)
+		log.Fatalf("failed connect to minio storage: %v", err)
 	}
 
 	k8s.InitKubeData()
 	kubeClient := k8s.NewKubeService(log)
 	err = kubeClient.Connect()
 	if err != nil {
-		log.Fatalf("failed connect to kubernetes: %w", err)
+		log.Fatalf("failed connect to kubernetes: %v", err)
 	}
 
 	deploymentData, err := kubeClient.GetDataFromDeployment()
 	if err != nil {
-		log.Fatalf("failed get deployment data: %w", err)
+		log.Fatalf("failed get deployment data: %v", err)
 	}
 
 	// Create alert sender
@@ -90,12 +113,66 @@ func main() {
 		log.WithError(err).Fatal("cannot parse monitoring options")
 	}
 
+	procNames := make([]string, 0, len(optsMap))
+	for proc := range optsMap {
+		procNames = append(procNames, proc)
+		if _, err = process.ProcessMatcher(proc); err != nil {
+			log.WithError(err).Fatal("invalid process match criteria")
+		}
+	}
+	if err = integritymonitor.ValidateResponseActions(procNames); err != nil {
+		log.WithError(err).Fatal("invalid response action")
+	}
+	if err = integritymonitor.ValidateAllowlists(procNames); err != nil {
+		log.WithError(err).Fatal("invalid process allowlist")
+	}
+	if err = integritymonitor.ValidateListenAllowlists(procNames); err != nil {
+		log.WithError(err).Fatal("invalid listen allowlist")
+	}
+	if err = integritymonitor.ValidateSizeLimit(); err != nil {
+		log.WithError(err).Fatal("invalid file size limit")
+	}
+	if err = integritymonitor.ValidateLearnMode(); err != nil {
+		log.WithError(err).Fatal("invalid baseline learning mode")
+	}
+	if viper.GetBool("api-enabled") {
+		if err = api.Validate(); err != nil {
+			log.WithError(err).Fatal("invalid api configuration")
+		}
+	}
+
 	// Run Application with graceful shutdown context
 	graceful.Execute(context.Background(), log, func(ctx context.Context) {
 		hbAlert := alerts.New("health check", alerts.HeartbeatEvent, "", common.AppId)
 		alerts.Heartbeat(ctx, log, hbAlert)
 
-		err := runCheckIntegrity(ctx, log, optsMap, deploymentData, kubeClient)
+		if addr := viper.GetString("metrics-addr"); addr != "" {
+			go func() {
+				if err := metrics.Serve(ctx, log, addr); err != nil {
+					log.WithError(err).Error("metrics server failed")
+				}
+			}()
+		}
+
+		// full scans requested by the watchers and the api
+		rescanC := make(chan string, len(optsMap))
+		if viper.GetBool("api-enabled") {
+			trigger := func(proc string) bool {
+				select {
+				case rescanC <- proc:
+					return true
+				default:
+					return false
+				}
+			}
+			go func() {
+				if err := api.New(log, kubeClient, procNames, trigger).Run(ctx); err != nil {
+					log.WithError(err).Error("api server failed")
+				}
+			}()
+		}
+
+		err := runCheckIntegrity(ctx, log, optsMap, deploymentData, kubeClient, rescanC)
 		if err == context.Canceled {
 			log.Info("execution cancelled")
 			return
@@ -111,27 +188,81 @@ func runCheckIntegrity(ctx context.Context,
 	log *logrus.Logger,
 	optsMap map[string][]string,
 	deploymentData *k8s.DeploymentData,
-	kubeClient *k8s.KubeClient) error {
+	kubeClient *k8s.KubeClient,
+	rescanC chan string) error {
 
-	var err error
-	t := time.NewTicker(viper.GetDuration("duration-time"))
-	for range t.C {
+	if viper.GetBool("watch-enabled") {
 		for proc, paths := range optsMap {
-			select {
-			case <-ctx.Done():
-				return ctx.Err()
-			default:
-				log.Info("running a next check loop..")
-			}
+			go runWatchIntegrity(ctx, log, proc, paths, deploymentData, kubeClient, rescanC)
+		}
+	}
 
-			err = integritymonitor.CheckIntegrity(ctx, log, proc, paths, deploymentData, kubeClient)
+	var err error
+	t := time.NewTicker(viper.GetDuration("duration-time"))
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case proc := <-rescanC:
+			log.WithField("process", proc).Info("running a requested full scan..")
+			err = integritymonitor.CheckIntegrity(ctx, log, proc, optsMap[proc], deploymentData, kubeClient)
 			if err != nil {
 				log.WithError(err).Error("failed check integrity")
 			}
+		case <-t.C:
+			err = integritymonitor.Rebaseline(ctx, log, optsMap, deploymentData, kubeClient)
+			if err != nil {
+				log.WithError(err).Error("failed rebaseline")
+			}
+			for proc, paths := range optsMap {
+				select {
+				case <-ctx.Done():
+					return ctx.Err()
+				default:
+					log.Info("running a next check loop..")
+				}
+
+				err = integritymonitor.CheckIntegrity(ctx, log, proc, paths, deploymentData, kubeClient)
+				if err != nil {
+					log.WithError(err).Error("failed check integrity")
+				}
+			}
 		}
 	}
+}
 
-	return nil
+// runWatchIntegrity keeps the real-time watching of the process running, the
+// watching is restarted after the "duration-time" interval if it stops (e.g.
+// the process is restarted). The full scan is requested after every restart
+// since the changes made meanwhile are not seen by the watcher.
+func runWatchIntegrity(ctx context.Context,
+	log *logrus.Logger,
+	proc string,
+	paths []string,
+	deploymentData *k8s.DeploymentData,
+	kubeClient *k8s.KubeClient,
+	rescanC chan<- string) {
+
+	for restarted := false; ; restarted = true {
+		if restarted {
+			select {
+			case rescanC <- proc:
+			default:
+			}
+		}
+		err := integritymonitor.WatchIntegrity(ctx, log, proc, paths, deploymentData, kubeClient, rescanC)
+		if ctx.Err() != nil {
+			return
+		}
+		log.WithError(err).WithField("process", proc).Warn("real-time watching stopped, restarting")
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(viper.GetDuration("duration-time")):
+		}
+	}
 }
 
 func initConfig() {
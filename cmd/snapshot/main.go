@@ -24,14 +24,18 @@ import (
   ./snapshot --root-fs="bin/docker-fs" --verbose=debug --dir "/app,/bin" --out "bin/snapshot.txt"
 
   Exporting docker image filesystem.
-  The code below will export the filesystem of the docker image "integrity:latest into the "./bin/docker-fs/":
-  cid=$(docker create integrity:latest) && docker export $cid | tar -xC ./bin/docker-fs/ && docker rm $cid
+  The code below will export the filesystem of the docker image "integrity:latest into the "./bin/docker-fs/",
+  the security xattrs are kept for the --with-metadata snapshots:
+  cid=$(docker create integrity:latest) && docker export $cid | tar --xattrs --xattrs-include='security.*' -xC ./bin/docker-fs/ && docker rm $cid
 */
 
 func main() {
 	initConfig()
 	initLog()
 
+	if err := integritymonitor.ValidateSizeLimit(); err != nil {
+		logrus.WithError(err).Fatal("invalid file size limit")
+	}
 	if err := integritymonitor.CalculateAndWriteHashes(); err != nil {
 		logrus.WithError(err).Error("failed to create output file")
 	}
@@ -41,6 +45,7 @@ func initConfig() {
 	pflag.StringSlice("dir", []string{}, "path to dir for which snapshot will be created, example: --dir=\"tmp,bin\" --dir vendor (result: [tmp bin vendor])")
 	pflag.String("root-fs", "./", "path to docker image root filesystem")
 	pflag.String("out", "out.txt", "output file name")
+	pflag.Bool("with-metadata", false, "store file metadata (mode, uid/gid, size, security xattrs) along with the hashes")
 	pflag.Duration("scan-dir-timeout", 30*time.Second, "timeout for scanning directory while creating hashes")
 	pflag.Parse()
 	viper.BindPFlags(pflag.CommandLine)
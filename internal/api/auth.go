@@ -0,0 +1,80 @@
+package api
+
+import (
+	"crypto/subtle"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// Validate checks that the API requests can be authenticated. The TokenReview
+// authenticates any service account of the cluster, so it requires the users
+// allowed to access the API.
+func Validate() error {
+	if viper.GetString("api-token") == "" && !viper.GetBool("api-token-review") {
+		return errors.New("api requires --api-token or --api-token-review")
+	}
+	if viper.GetBool("api-token-review") && len(viper.GetStringSlice("api-allowed-users")) == 0 {
+		return errors.New("--api-token-review requires --api-allowed-users")
+	}
+	return nil
+}
+
+// authenticate passes the requests with the bearer token equal to the
+// "api-token" or, with "api-token-review", the token of a user authenticated
+// by the TokenReview API and listed in "api-allowed-users".
+func (s *Server) authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, ok := bearerToken(r)
+		if !ok {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			writeError(w, http.StatusUnauthorized, "bearer token required")
+			return
+		}
+
+		if static := viper.GetString("api-token"); static != "" &&
+			subtle.ConstantTimeCompare([]byte(token), []byte(static)) == 1 {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if !viper.GetBool("api-token-review") {
+			writeError(w, http.StatusUnauthorized, "invalid token")
+			return
+		}
+
+		user, err := s.kubeClient.AuthenticateToken(r.Context(), token)
+		if err != nil {
+			s.log.WithError(err).Warn("api token rejected")
+			writeError(w, http.StatusUnauthorized, "invalid token")
+			return
+		}
+		if !userAllowed(user) {
+			s.log.WithField("user", user).Warn("api access denied")
+			writeError(w, http.StatusForbidden, "access denied for "+user)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func bearerToken(r *http.Request) (string, bool) {
+	const prefix = "Bearer "
+	h := r.Header.Get("Authorization")
+	if len(h) <= len(prefix) || !strings.EqualFold(h[:len(prefix)], prefix) {
+		return "", false
+	}
+	return strings.TrimSpace(h[len(prefix):]), true
+}
+
+// userAllowed reports whether the @user is listed in "api-allowed-users",
+// nobody is allowed if the list is empty.
+func userAllowed(user string) bool {
+	for _, u := range viper.GetStringSlice("api-allowed-users") {
+		if u == user {
+			return true
+		}
+	}
+	return false
+}
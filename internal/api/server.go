@@ -0,0 +1,175 @@
+// Package api implements the HTTP API of the monitor: the scan results, the
+// scan history, on-demand scans and the effective configuration.
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+
+	"github.com/ScienceSoft-Inc/integrity-sum/internal/integritymonitor"
+	"github.com/ScienceSoft-Inc/integrity-sum/pkg/k8s"
+)
+
+const shutdownTimeout = 5 * time.Second
+
+// TriggerFunc requests an immediate scan of the @proc process. Returns false
+// if the queue of the requested scans is full.
+type TriggerFunc func(proc string) bool
+
+// Server serves the HTTP API.
+type Server struct {
+	log        *logrus.Logger
+	kubeClient k8s.IKuberService
+	processes  []string
+	trigger    TriggerFunc
+}
+
+// New returns the API server of the monitored @processes.
+func New(log *logrus.Logger, kubeClient k8s.IKuberService, processes []string, trigger TriggerFunc) *Server {
+	procs := append([]string(nil), processes...)
+	sort.Strings(procs)
+	return &Server{log: log, kubeClient: kubeClient, processes: procs, trigger: trigger}
+}
+
+// Run serves the API on the "api-addr" address until the @ctx is done.
+func (s *Server) Run(ctx context.Context) error {
+	srv := &http.Server{
+		Addr:              viper.GetString("api-addr"),
+		Handler:           s.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		if err := srv.Shutdown(shutdownCtx); err != nil {
+			s.log.WithError(err).Error("failed shutdown api server")
+		}
+	}()
+
+	s.log.WithField("addr", srv.Addr).Info("api server started")
+	if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// Handler returns the authenticated API handler.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/results", s.method(http.MethodGet, s.handleResults))
+	mux.HandleFunc("/api/v1/history", s.method(http.MethodGet, s.handleHistory))
+	mux.HandleFunc("/api/v1/scan", s.method(http.MethodPost, s.handleScan))
+	mux.HandleFunc("/api/v1/config", s.method(http.MethodGet, s.handleConfig))
+	return s.authenticate(mux)
+}
+
+func (s *Server) method(method string, h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != method {
+			w.Header().Set("Allow", method)
+			writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+			return
+		}
+		h(w, r)
+	}
+}
+
+// handleResults returns the last scan result of every process.
+func (s *Server) handleResults(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, integritymonitor.LastResults())
+}
+
+// handleHistory returns the scan history of the process from the "process"
+// parameter or of all the processes.
+func (s *Server) handleHistory(w http.ResponseWriter, r *http.Request) {
+	procs, ok := s.requestedProcesses(w, r)
+	if !ok {
+		return
+	}
+	history := make(map[string][]integritymonitor.ScanResult, len(procs))
+	for _, p := range procs {
+		history[p] = integritymonitor.History(p)
+	}
+	writeJSON(w, http.StatusOK, history)
+}
+
+// handleScan requests an immediate scan of the process from the "process"
+// parameter or of all the processes.
+func (s *Server) handleScan(w http.ResponseWriter, r *http.Request) {
+	procs, ok := s.requestedProcesses(w, r)
+	if !ok {
+		return
+	}
+	resp := struct {
+		Requested []string `json:"requested"`
+		Rejected  []string `json:"rejected,omitempty"`
+	}{Requested: []string{}}
+	for _, p := range procs {
+		if s.trigger(p) {
+			resp.Requested = append(resp.Requested, p)
+		} else {
+			resp.Rejected = append(resp.Rejected, p)
+		}
+	}
+	s.log.WithField("processes", procs).Info("scan requested through the api")
+	writeJSON(w, http.StatusAccepted, resp)
+}
+
+// handleConfig returns the effective configuration, the secrets are redacted.
+func (s *Server) handleConfig(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, redact(viper.AllSettings()))
+}
+
+func (s *Server) requestedProcesses(w http.ResponseWriter, r *http.Request) ([]string, bool) {
+	proc := r.URL.Query().Get("process")
+	if proc == "" {
+		return s.processes, true
+	}
+	for _, p := range s.processes {
+		if p == proc {
+			return []string{p}, true
+		}
+	}
+	writeError(w, http.StatusNotFound, "unknown process "+proc)
+	return nil, false
+}
+
+var secretKeys = []string{"password", "secret", "token", "key"}
+
+// redact hides the values of the @settings which look like secrets.
+func redact(settings map[string]interface{}) map[string]interface{} {
+	for k, v := range settings {
+		if nested, ok := v.(map[string]interface{}); ok {
+			settings[k] = redact(nested)
+			continue
+		}
+		for _, s := range secretKeys {
+			if strings.Contains(strings.ToLower(k), s) {
+				if str, ok := v.(string); !ok || str != "" {
+					settings[k] = "<redacted>"
+				}
+				break
+			}
+		}
+	}
+	return settings
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, msg string) {
+	writeJSON(w, status, map[string]string{"error": msg})
+}
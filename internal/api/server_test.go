@@ -0,0 +1,102 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	mockk8s "github.com/ScienceSoft-Inc/integrity-sum/pkg/k8s/mocks"
+)
+
+func request(t *testing.T, h http.Handler, method, url, token string) *httptest.ResponseRecorder {
+	t.Helper()
+	r := httptest.NewRequest(method, url, nil)
+	if token != "" {
+		r.Header.Set("Authorization", "Bearer "+token)
+	}
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	return w
+}
+
+func TestAuthentication(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	kc := mockk8s.NewMockIKuberService(ctrl)
+
+	viper.Set("api-token", "static")
+	defer func() {
+		viper.Set("api-token", nil)
+		viper.Set("api-token-review", nil)
+		viper.Set("api-allowed-users", nil)
+	}()
+	h := New(logrus.New(), kc, []string{"nginx"}, nil).Handler()
+
+	assert.Equal(t, http.StatusUnauthorized, request(t, h, http.MethodGet, "/api/v1/results", "").Code)
+	assert.Equal(t, http.StatusUnauthorized, request(t, h, http.MethodGet, "/api/v1/results", "wrong").Code)
+	assert.Equal(t, http.StatusOK, request(t, h, http.MethodGet, "/api/v1/results", "static").Code)
+
+	viper.Set("api-token-review", true)
+	viper.Set("api-allowed-users", []string{"system:serviceaccount:monitoring:scanner"})
+	kc.EXPECT().AuthenticateToken(gomock.Any(), "sa-token").Return("system:serviceaccount:monitoring:scanner", nil)
+	kc.EXPECT().AuthenticateToken(gomock.Any(), "other").Return("system:serviceaccount:default:app", nil)
+	kc.EXPECT().AuthenticateToken(gomock.Any(), "bad").Return("", errors.New("token is not authenticated"))
+	assert.Equal(t, http.StatusOK, request(t, h, http.MethodGet, "/api/v1/results", "sa-token").Code)
+	assert.Equal(t, http.StatusForbidden, request(t, h, http.MethodGet, "/api/v1/results", "other").Code)
+	assert.Equal(t, http.StatusUnauthorized, request(t, h, http.MethodGet, "/api/v1/results", "bad").Code)
+
+	// nobody is allowed without the allowed users
+	viper.Set("api-allowed-users", nil)
+	kc.EXPECT().AuthenticateToken(gomock.Any(), "sa-token").Return("system:serviceaccount:monitoring:scanner", nil)
+	assert.Equal(t, http.StatusForbidden, request(t, h, http.MethodGet, "/api/v1/results", "sa-token").Code)
+
+	viper.Set("api-token", "")
+	assert.Error(t, Validate())
+	viper.Set("api-allowed-users", []string{"system:serviceaccount:monitoring:scanner"})
+	assert.NoError(t, Validate())
+	viper.Set("api-token-review", false)
+	assert.Error(t, Validate())
+}
+
+func TestScanAndConfig(t *testing.T) {
+	viper.Set("api-token", "static")
+	viper.Set("minio-secret-key", "s3cr3t")
+	defer func() {
+		viper.Set("api-token", nil)
+		viper.Set("minio-secret-key", nil)
+	}()
+
+	var requested []string
+	trigger := func(proc string) bool {
+		requested = append(requested, proc)
+		return proc != "redis"
+	}
+	h := New(logrus.New(), nil, []string{"redis", "nginx"}, trigger).Handler()
+
+	assert.Equal(t, http.StatusMethodNotAllowed, request(t, h, http.MethodGet, "/api/v1/scan", "static").Code)
+	assert.Equal(t, http.StatusNotFound, request(t, h, http.MethodPost, "/api/v1/scan?process=ghost", "static").Code)
+
+	w := request(t, h, http.MethodPost, "/api/v1/scan", "static")
+	assert.Equal(t, http.StatusAccepted, w.Code)
+	assert.JSONEq(t, `{"requested":["nginx"],"rejected":["redis"]}`, w.Body.String())
+	assert.Equal(t, []string{"nginx", "redis"}, requested)
+
+	w = request(t, h, http.MethodGet, "/api/v1/history?process=nginx", "static")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"nginx":[]}`, w.Body.String())
+
+	w = request(t, h, http.MethodGet, "/api/v1/config", "static")
+	require.Equal(t, http.StatusOK, w.Code)
+	var cfg map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &cfg))
+	assert.Equal(t, "<redacted>", cfg["minio-secret-key"])
+	assert.Equal(t, "<redacted>", cfg["api-token"])
+}
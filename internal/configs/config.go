@@ -11,11 +11,14 @@ import (
 )
 
 const (
-	procDir      = "/proc"
-	durationTime = 30 * time.Second
-	algorithm    = "SHA256"
-	monitorOpts  = ""
-	clusterName  = "local"
+	procDir          = "/proc"
+	durationTime     = 30 * time.Second
+	algorithm        = "SHA256"
+	monitorOpts      = ""
+	clusterName      = "local"
+	responseAction   = "delete"
+	fullRehashCycles = 10
+	oversizePolicy   = "head-tail"
 )
 
 func init() {
@@ -36,6 +39,34 @@ func init() {
 	fsSum.String("monitoring-options", monitorOpts, "process name and process paths to monitoring, should be represented as key=value pair. e.g. nginx=/dir1,/dir2")
 	fsSum.StringToString("process-image", map[string]string{}, "mapping process name to image name, should be represented as key=value pair. e.g. nginx=nginx:v1.4,redis=redis:v1.0 ")
 	fsSum.String("cluster-name", clusterName, "Name of cluster where monitor deployed, default local")
+	fsSum.StringArray("process-match", nil, "process name and its match criteria, should be represented as key=kind:value[;kind:value] pair, the flag is repeated for every process. e.g. --process-match=nginx=exe:/usr/sbin/nginx --process-match=app=cmdline:app\\.py;cgroup:3f4e2c. Available kinds: comm, exe, cmdline, cgroup. By default the process name is matched to comm or executable name")
+	fsSum.StringArray("process-allowlist", nil, "process name and the processes permitted to run in its container, should be represented as key=exe[:cmdline][;exe[:cmdline]] pair, the flag is repeated for every process. e.g. nginx=/usr/sbin/nginx;/bin/sh:^sh -c /healthcheck\\.sh$. Executable is a glob pattern, cmdline is a regular expression, commas are not separators")
+	fsSum.StringToString("listen-allowlist", map[string]string{}, "mapping process name to the listening sockets expected in its pod, should be represented as key=proto:[ip:]port[;proto:[ip:]port] pair. e.g. nginx=tcp:80;tcp6:80. Processes without it learn the baseline during listen-learn-period and store it in MinIO")
+	fsSum.StringToString("response-action", map[string]string{}, "mapping process name to response action on integrity violation, should be represented as key=value pair. e.g. nginx=evict,redis=alert. Available actions: alert, delete, evict, scale-to-zero, quarantine, rollback, restore")
+	fsSum.String("default-response-action", responseAction, "response action for processes without own --response-action")
+	fsSum.Bool("audit", false, "audit mode for all processes: violations are logged and alerted with the audit marker, the response actions are not performed")
+	fsSum.StringSlice("audit-processes", nil, "comma separated names of the processes monitored in the audit mode")
+	fsSum.String("evidence-bucket", "", "MinIO bucket to copy the violating files into before the response action, empty disables the evidence capture")
+	fsSum.Int64("evidence-max-file-size", 10<<20, "size limit in bytes of a violating file copied as the evidence, 0 disables the limit")
+	fsSum.String("golden-bucket", "", "MinIO bucket of the content-addressed golden copies of the snapshot files used by the restore response action, the copies are uploaded when the snapshot is taken")
+	fsSum.Bool("k8s-events", true, "create Warning events naming the process, the path and the violation type on the pod and its owner on integrity violations")
+	fsSum.Bool("scan-annotations", true, "store the time and the result of the last scan of every process in the <process>.integrity-monitor.scnsoft.com/last-scan and last-scan-result pod annotations")
+	fsSum.Bool("integrity-reports", false, "store the result of the last scan of every process in the IntegrityReport CR owned by the pod, the IntegrityReport CRD of the snapshot-controller should be installed")
+	fsSum.Int("scan-history", 20, "number of the last scan results of every process kept for the HTTP API")
+	fsSum.Bool("full-diff", false, "walk the whole file tree and report all integrity violations instead of stopping at the first one")
+	fsSum.Bool("stat-cache", true, "do not rehash files whose path, inode, size, mtime and ctime have not changed since the previous scan")
+	fsSum.Int("full-rehash-cycles", fullRehashCycles, "rehash all files ignoring the stat cache every N scan cycles, 0 disables it")
+	fsSum.Int64("max-file-size", 0, "size limit of a file in bytes, the oversize-policy is applied to larger files, 0 disables the limit")
+	fsSum.String("oversize-policy", oversizePolicy, "policy for files exceeding max-file-size: skip, head-tail or violation")
+	fsSum.Int64("max-bytes-per-sec", 0, "limit of bytes read per second by all scan workers, 0 means no limit")
+	fsSum.Int("max-files-per-sec", 0, "limit of files processed per second by all scan workers, 0 means no limit")
+	fsSum.Bool("low-priority", false, "run scan workers with the lowest CPU and I/O priority")
+	fsSum.Bool("verify-executables", false, "verify the executables and the loaded shared libraries of the monitored processes, the snapshot should contain them")
+	fsSum.Bool("verify-listeners", false, "detect unexpected listening TCP and UDP sockets of the monitored processes")
+	fsSum.Duration("listen-learn-period", time.Minute, "period after the start to learn the listening sockets of the processes without listen-allowlist")
+	fsSum.String("learn-baseline", "off", "learn the snapshot of a process from its running pod if the snapshot does not exist (trust on first use): off, minio or snapshot. The snapshot mode creates the Snapshot CR")
+	fsSum.Bool("watch-enabled", false, "detect file changes in real time with inotify, the periodic full scan is still running")
+	fsSum.Duration("watch-debounce", time.Second, "interval to collect file change events before verifying the changed files")
 	pflag.CommandLine.AddFlagSet(fsSum)
 	if err := viper.BindPFlags(fsSum); err != nil {
 		fmt.Printf("error binding flags: %v", err)
@@ -57,6 +88,30 @@ func init() {
 	viper.BindEnv("splunk-token", "SPLUNK_TOKEN")
 	viper.BindEnv("splunk-insecure-skip-verify", "SPLUNK_INSECURE_SKIP_VERIFY")
 
+	fsAPI := pflag.NewFlagSet("api", pflag.ContinueOnError)
+	fsAPI.Bool("api-enabled", false, "Enable HTTP API")
+	fsAPI.String("api-addr", ":8080", "HTTP API listen address")
+	fsAPI.String("api-token", "", "HTTP API static bearer token")
+	fsAPI.Bool("api-token-review", false, "Authenticate HTTP API bearer tokens with the Kubernetes TokenReview API")
+	fsAPI.String("metrics-addr", "", "Prometheus /metrics endpoint listen address, e.g. :9090. Disabled if empty")
+	fsAPI.StringSlice("api-allowed-users", nil, "Users authenticated by the TokenReview allowed to access HTTP API, e.g. system:serviceaccount:monitoring:prometheus. Required with api-token-review")
+	pflag.CommandLine.AddFlagSet(fsAPI)
+	if err := viper.BindPFlags(fsAPI); err != nil {
+		fmt.Printf("error binding flags: %v", err)
+		os.Exit(1)
+	}
+	viper.BindEnv("api-token", "API_TOKEN")
+
+	fsTr := pflag.NewFlagSet("tracing", pflag.ContinueOnError)
+	fsTr.String("tracing-endpoint", "", "OpenTelemetry collector OTLP/gRPC endpoint to export the scan traces to, e.g. otel-collector.monitoring:4317. Disabled if empty")
+	fsTr.Bool("tracing-insecure", false, "Disable TLS of the connection to the OpenTelemetry collector")
+	fsTr.Float64("tracing-sample-ratio", 1, "Fraction of the scans traced, from 0 to 1")
+	pflag.CommandLine.AddFlagSet(fsTr)
+	if err := viper.BindPFlags(fsTr); err != nil {
+		fmt.Printf("error binding flags: %v", err)
+		os.Exit(1)
+	}
+
 	fsSys := pflag.NewFlagSet("syslog", pflag.ContinueOnError)
 	fsSys.Bool("syslog-enabled", false, "Enable syslog alerts")
 	fsSys.String("syslog-host", "localhost", "Syslog server host")
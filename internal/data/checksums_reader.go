@@ -44,8 +44,44 @@ func (fs *ChecksumsReader) parseRecord(rec string) (*HashDataOutput, error) {
 		return nil, fmt.Errorf("incorrect hash record %s", rec)
 	}
 
-	return &HashDataOutput{
+	out := &HashDataOutput{
 		Hash:         strings.TrimSpace(parts[0]),
 		FullFileName: strings.TrimSpace(parts[1]),
-	}, nil
+	}
+	// optional type and metadata follow the file name
+	if len(parts) > 2 {
+		var (
+			rest []string
+			err  error
+		)
+		out.Type, out.Target, rest, err = parseType(strings.Fields(strings.Join(parts[2:], " ")))
+		if err != nil {
+			return nil, fmt.Errorf("incorrect hash record %s: %w", rec, err)
+		}
+		if len(rest) > 0 {
+			out.Meta, err = ParseFileMeta(strings.Join(rest, " "))
+			if err != nil {
+				return nil, fmt.Errorf("incorrect hash record %s: %w", rec, err)
+			}
+		}
+	}
+	return out, nil
+}
+
+// FormatRecord returns the snapshot record for the file, @typ, @target and
+// @meta are optional.
+func FormatRecord(hash, path, typ, target string, meta *FileMeta) string {
+	const separator = "  "
+	rec := hash + separator + path
+	extra := formatType(typ, target)
+	if meta != nil {
+		if extra != "" {
+			extra += " "
+		}
+		extra += meta.String()
+	}
+	if extra != "" {
+		rec += separator + extra
+	}
+	return rec
 }
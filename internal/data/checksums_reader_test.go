@@ -4,6 +4,8 @@ import (
 	"io"
 	"reflect"
 	"testing"
+
+	"github.com/stretchr/testify/assert"
 )
 
 func TestFileStorage_parseRecord(t *testing.T) {
@@ -32,6 +34,60 @@ func TestFileStorage_parseRecord(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "parse record with metadata",
+			fields: fields{
+				r: nil,
+			},
+			args: args{rec: "f37852d0113de30fa6bfc3d9b180ef99383c06739530dd482a8538503afd5a58  usr/bin/ping  mode=4755 uid=0 gid=0 size=64 xattr.security.capability=AQAAAg=="},
+			want: &HashDataOutput{
+				Hash:         "f37852d0113de30fa6bfc3d9b180ef99383c06739530dd482a8538503afd5a58",
+				FullFileName: "usr/bin/ping",
+				Meta: &FileMeta{
+					Mode:   04755,
+					Size:   64,
+					Xattrs: map[string]string{"security.capability": "AQAAAg=="},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "parse symlink record",
+			fields: fields{
+				r: nil,
+			},
+			args: args{rec: "-  usr/bin/python  type=symlink target=%2Fopt%2Fmy%20python mode=777 uid=0 gid=0 size=15"},
+			want: &HashDataOutput{
+				Hash:         "-",
+				FullFileName: "usr/bin/python",
+				Type:         TypeSymlink,
+				Target:       "/opt/my python",
+				Meta:         &FileMeta{Mode: 0777, Size: 15},
+			},
+			wantErr: false,
+		},
+		{
+			name: "parse device record",
+			fields: fields{
+				r: nil,
+			},
+			args: args{rec: "-  dev/null  type=char rdev=1:3"},
+			want: &HashDataOutput{
+				Hash:         "-",
+				FullFileName: "dev/null",
+				Type:         TypeCharDev,
+				Target:       "1:3",
+			},
+			wantErr: false,
+		},
+		{
+			name: "parse record with incorrect metadata",
+			fields: fields{
+				r: nil,
+			},
+			args:    args{rec: "f37852d0113de30fa6bfc3d9b180ef99383c06739530dd482a8538503afd5a58  usr/bin/ping  mode=abc"},
+			wantErr: true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -49,3 +105,12 @@ func TestFileStorage_parseRecord(t *testing.T) {
 		})
 	}
 }
+
+func TestFormatRecord(t *testing.T) {
+	assert.Equal(t, "abc  etc/file", FormatRecord("abc", "etc/file", "", "", nil))
+	assert.Equal(t, "-  usr/bin/python  type=symlink target=%2Fopt%2Fmy%20python",
+		FormatRecord(NoHash, "usr/bin/python", TypeSymlink, "/opt/my python", nil))
+	assert.Equal(t, "-  dev/null  type=char rdev=1:3 mode=666 uid=0 gid=0 size=0",
+		FormatRecord(NoHash, "dev/null", TypeCharDev, "1:3", &FileMeta{Mode: 0666}))
+	assert.Equal(t, "-  etc  type=dir", FormatRecord(NoHash, "etc", TypeDir, "", nil))
+}
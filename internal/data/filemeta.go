@@ -0,0 +1,230 @@
+package data
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+const xattrPrefix = "xattr."
+
+// Extended attributes namespace which is stored in the snapshot
+const securityXattrs = "security."
+
+// FileMeta holds file metadata which might be stored in a snapshot record
+// along with the file hash.
+type FileMeta struct {
+	Mode   uint32 // permission bits with setuid, setgid and sticky bits
+	Uid    uint32
+	Gid    uint32
+	Size   int64
+	Xattrs map[string]string // security xattrs, values are base64 encoded
+}
+
+// StatFileMeta returns metadata of the @path file, symlinks are not followed.
+func StatFileMeta(path string) (*FileMeta, error) {
+	info, err := os.Lstat(path)
+	if err != nil {
+		return nil, err
+	}
+	meta := FileMetaOf(info)
+	meta.Xattrs, err = SecurityXattrs(path)
+	if err != nil {
+		return nil, err
+	}
+	return meta, nil
+}
+
+// FileMetaOf returns the metadata of the file from its Lstat @info without the
+// extended attributes.
+func FileMetaOf(info fs.FileInfo) *FileMeta {
+	meta := &FileMeta{
+		Mode: unixMode(info.Mode()),
+		Size: info.Size(),
+	}
+	if st, ok := info.Sys().(*syscall.Stat_t); ok {
+		meta.Uid, meta.Gid = st.Uid, st.Gid
+	}
+	return meta
+}
+
+func unixMode(m fs.FileMode) uint32 {
+	mode := uint32(m.Perm())
+	if m&fs.ModeSetuid != 0 {
+		mode |= syscall.S_ISUID
+	}
+	if m&fs.ModeSetgid != 0 {
+		mode |= syscall.S_ISGID
+	}
+	if m&fs.ModeSticky != 0 {
+		mode |= syscall.S_ISVTX
+	}
+	return mode
+}
+
+// FileMode returns the permission bits of the metadata as fs.FileMode.
+func (m *FileMeta) FileMode() fs.FileMode {
+	mode := fs.FileMode(m.Mode).Perm()
+	if m.Mode&syscall.S_ISUID != 0 {
+		mode |= fs.ModeSetuid
+	}
+	if m.Mode&syscall.S_ISGID != 0 {
+		mode |= fs.ModeSetgid
+	}
+	if m.Mode&syscall.S_ISVTX != 0 {
+		mode |= fs.ModeSticky
+	}
+	return mode
+}
+
+// SecurityXattrs returns the security extended attributes of the @path file,
+// symlinks are not followed.
+func SecurityXattrs(path string) (map[string]string, error) {
+	size, err := unix.Llistxattr(path, nil)
+	if err != nil || size == 0 {
+		// xattrs are not supported by the file system
+		if errors.Is(err, unix.ENOTSUP) {
+			err = nil
+		}
+		return nil, err
+	}
+	buf := make([]byte, size)
+	size, err = unix.Llistxattr(path, buf)
+	if err != nil {
+		return nil, err
+	}
+
+	var xattrs map[string]string
+	for _, name := range strings.Split(string(buf[:size]), "\x00") {
+		if !strings.HasPrefix(name, securityXattrs) {
+			continue
+		}
+		vSize, err := unix.Lgetxattr(path, name, nil)
+		if err != nil {
+			return nil, err
+		}
+		value := make([]byte, vSize)
+		if vSize, err = unix.Lgetxattr(path, name, value); err != nil {
+			return nil, err
+		}
+		if xattrs == nil {
+			xattrs = make(map[string]string)
+		}
+		xattrs[name] = base64.StdEncoding.EncodeToString(value[:vSize])
+	}
+	return xattrs, nil
+}
+
+// String returns the metadata as space separated key=value pairs, e.g.
+// "mode=4755 uid=0 gid=0 size=1024 xattr.security.capability=AQAAAgAgAAAAAAAAAAAAAAAAAAA="
+func (m *FileMeta) String() string {
+	fields := m.fields()
+	pairs := make([]string, 0, len(fields))
+	for _, k := range []string{"mode", "uid", "gid", "size"} {
+		pairs = append(pairs, k+"="+fields[k])
+	}
+	xattrs := make([]string, 0, len(m.Xattrs))
+	for k, v := range m.Xattrs {
+		xattrs = append(xattrs, xattrPrefix+k+"="+v)
+	}
+	sort.Strings(xattrs)
+	return strings.Join(append(pairs, xattrs...), " ")
+}
+
+func (m *FileMeta) fields() map[string]string {
+	fields := map[string]string{
+		"mode": strconv.FormatUint(uint64(m.Mode), 8),
+		"uid":  strconv.FormatUint(uint64(m.Uid), 10),
+		"gid":  strconv.FormatUint(uint64(m.Gid), 10),
+		"size": strconv.FormatInt(m.Size, 10),
+	}
+	for k, v := range m.Xattrs {
+		fields[xattrPrefix+k] = v
+	}
+	return fields
+}
+
+// ParseFileMeta parses metadata represented by the FileMeta.String() format.
+func ParseFileMeta(s string) (*FileMeta, error) {
+	meta := &FileMeta{}
+	for _, pair := range strings.Fields(s) {
+		k, v, ok := strings.Cut(pair, "=")
+		if !ok {
+			return nil, fmt.Errorf("incorrect metadata field %q", pair)
+		}
+
+		var err error
+		switch {
+		case k == "mode":
+			var mode uint64
+			mode, err = strconv.ParseUint(v, 8, 32)
+			meta.Mode = uint32(mode)
+		case k == "uid":
+			var uid uint64
+			uid, err = strconv.ParseUint(v, 10, 32)
+			meta.Uid = uint32(uid)
+		case k == "gid":
+			var gid uint64
+			gid, err = strconv.ParseUint(v, 10, 32)
+			meta.Gid = uint32(gid)
+		case k == "size":
+			meta.Size, err = strconv.ParseInt(v, 10, 64)
+		case strings.HasPrefix(k, xattrPrefix):
+			if meta.Xattrs == nil {
+				meta.Xattrs = make(map[string]string)
+			}
+			meta.Xattrs[strings.TrimPrefix(k, xattrPrefix)] = v
+		default:
+			err = fmt.Errorf("unknown metadata field %q", k)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("incorrect metadata field %q: %w", pair, err)
+		}
+	}
+	return meta, nil
+}
+
+// Diff compares the metadata of the @typ type file with the @actual one and
+// returns the differing attributes of both as key=value pairs. The size of a
+// directory depends on the file system and the size of a symlink mirrors its
+// target, so it is compared for the other types only.
+func (m *FileMeta) Diff(actual *FileMeta, typ string) (expected, got []string) {
+	ef, af := m.fields(), actual.fields()
+	if typ == TypeDir || typ == TypeSymlink {
+		delete(ef, "size")
+		delete(af, "size")
+	}
+	keys := make([]string, 0, len(ef)+len(af))
+	for k := range ef {
+		keys = append(keys, k)
+	}
+	for k := range af {
+		if _, ok := ef[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		ev, eok := ef[k]
+		av, aok := af[k]
+		if ev == av && eok == aok {
+			continue
+		}
+		if eok {
+			expected = append(expected, k+"="+ev)
+		}
+		if aok {
+			got = append(got, k+"="+av)
+		}
+	}
+	return expected, got
+}
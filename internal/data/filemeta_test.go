@@ -0,0 +1,72 @@
+package data
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileMetaString(t *testing.T) {
+	meta := &FileMeta{
+		Mode:   02750,
+		Uid:    1000,
+		Gid:    50,
+		Size:   12,
+		Xattrs: map[string]string{"security.selinux": "c3lzdGVt", "security.capability": "AQAAAg=="},
+	}
+	s := meta.String()
+	assert.Equal(t, "mode=2750 uid=1000 gid=50 size=12 xattr.security.capability=AQAAAg== xattr.security.selinux=c3lzdGVt", s)
+
+	parsed, err := ParseFileMeta(s)
+	assert.NoError(t, err)
+	assert.Equal(t, meta, parsed)
+
+	_, err = ParseFileMeta("mode=755 owner=root")
+	assert.Error(t, err)
+}
+
+func TestFileMetaDiff(t *testing.T) {
+	expected := &FileMeta{Mode: 0755, Size: 10, Xattrs: map[string]string{"security.selinux": "a"}}
+	actual := &FileMeta{Mode: 04755, Size: 10, Xattrs: map[string]string{"security.capability": "b"}}
+
+	exp, act := expected.Diff(actual, "")
+	assert.Equal(t, []string{"mode=755", "xattr.security.selinux=a"}, exp)
+	assert.Equal(t, []string{"mode=4755", "xattr.security.capability=b"}, act)
+
+	exp, act = expected.Diff(expected, "")
+	assert.Empty(t, exp)
+	assert.Empty(t, act)
+
+	// the directory size differs between the image and the overlayfs of the pod
+	expected = &FileMeta{Mode: 0755, Size: 4096}
+	actual = &FileMeta{Mode: 0755, Size: 60}
+	exp, act = expected.Diff(actual, TypeDir)
+	assert.Empty(t, exp)
+	assert.Empty(t, act)
+	exp, act = expected.Diff(actual, TypeSymlink)
+	assert.Empty(t, exp)
+	assert.Empty(t, act)
+	exp, act = expected.Diff(actual, "")
+	assert.Equal(t, []string{"size=4096"}, exp)
+	assert.Equal(t, []string{"size=60"}, act)
+
+	// the other attributes of a directory are still compared
+	actual.Mode = 0777
+	exp, act = expected.Diff(actual, TypeDir)
+	assert.Equal(t, []string{"mode=755"}, exp)
+	assert.Equal(t, []string{"mode=777"}, act)
+}
+
+func TestStatFileMeta(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), "file")
+	assert.NoError(t, os.WriteFile(fileName, []byte("data"), 0640))
+	assert.NoError(t, os.Chmod(fileName, 0640|os.ModeSetgid))
+
+	meta, err := StatFileMeta(fileName)
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(02640), meta.Mode)
+	assert.Equal(t, int64(4), meta.Size)
+	assert.Equal(t, uint32(os.Getuid()), meta.Uid)
+}
@@ -0,0 +1,89 @@
+package data
+
+import (
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"strings"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+// Types of the non-regular files stored in a snapshot, regular files have an
+// empty type
+const (
+	TypeDir      = "dir"
+	TypeSymlink  = "symlink"
+	TypeCharDev  = "char"
+	TypeBlockDev = "block"
+	TypeFifo     = "fifo"
+)
+
+// NoHash is stored instead of the hash for the non-regular files
+const NoHash = "-"
+
+// FileType returns the snapshot type of the @path file described by the
+// @info and its target: the link target for symlinks and "major:minor" for
+// device nodes. Returns ok=false for the file types which are not tracked
+// (e.g. sockets).
+func FileType(path string, info fs.FileInfo) (typ, target string, ok bool, err error) {
+	mode := info.Mode()
+	switch {
+	case mode.IsRegular():
+		return "", "", true, nil
+	case mode.IsDir():
+		return TypeDir, "", true, nil
+	case mode&fs.ModeSymlink != 0:
+		target, err = os.Readlink(path)
+		return TypeSymlink, target, true, err
+	case mode&fs.ModeNamedPipe != 0:
+		return TypeFifo, "", true, nil
+	case mode&fs.ModeDevice != 0:
+		typ = TypeBlockDev
+		if mode&fs.ModeCharDevice != 0 {
+			typ = TypeCharDev
+		}
+		if st, isStat := info.Sys().(*syscall.Stat_t); isStat {
+			rdev := uint64(st.Rdev) //nolint:unconvert // Rdev type differs between platforms
+			target = fmt.Sprintf("%d:%d", unix.Major(rdev), unix.Minor(rdev))
+		}
+		return typ, target, true, nil
+	}
+	return "", "", false, nil
+}
+
+// formatType returns the type and target of a record as key=value pairs.
+func formatType(typ, target string) string {
+	switch typ {
+	case "":
+		return ""
+	case TypeSymlink:
+		return "type=" + typ + " target=" + url.PathEscape(target)
+	case TypeCharDev, TypeBlockDev:
+		return "type=" + typ + " rdev=" + target
+	}
+	return "type=" + typ
+}
+
+// parseType extracts the type and target of a record from the @fields and
+// returns the rest of them.
+func parseType(fields []string) (typ, target string, rest []string, err error) {
+	for _, f := range fields {
+		switch {
+		case strings.HasPrefix(f, "type="):
+			typ = strings.TrimPrefix(f, "type=")
+		case strings.HasPrefix(f, "target="):
+			target, err = url.PathUnescape(strings.TrimPrefix(f, "target="))
+			if err != nil {
+				return "", "", nil, fmt.Errorf("incorrect symlink target %q: %w", f, err)
+			}
+		case strings.HasPrefix(f, "rdev="):
+			target = strings.TrimPrefix(f, "rdev=")
+		default:
+			rest = append(rest, f)
+		}
+	}
+	return typ, target, rest, nil
+}
@@ -15,4 +15,7 @@ type HashDataOutput struct {
 	Algorithm    string
 	NamePod      string
 	ReleaseId    int
+	Meta         *FileMeta // optional, nil if the record has no metadata
+	Type         string    // empty for regular files
+	Target       string    // symlink target or device "major:minor"
 }
@@ -0,0 +1,65 @@
+package integritymonitor
+
+import (
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/ScienceSoft-Inc/integrity-sum/internal/utils/process"
+)
+
+// processAllowlist returns the allowlist configured for the @procName process
+// with the "process-allowlist" option, nil if there is no allowlist.
+func processAllowlist(procName string) (process.Allowlist, error) {
+	spec, ok := process.Options("process-allowlist")[procName]
+	if !ok {
+		return nil, nil
+	}
+	a, err := process.ParseAllowlist(spec)
+	if err != nil {
+		return nil, fmt.Errorf("process %s: %w", procName, err)
+	}
+	return a, nil
+}
+
+// ValidateAllowlists checks the process allowlists configured for the
+// @procNames processes.
+func ValidateAllowlists(procNames []string) error {
+	for _, proc := range procNames {
+		if _, err := processAllowlist(proc); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// verifyProcesses reports the processes running in the containers of the
+// @processName instances which are not permitted by the @allowlist. The
+// instances of the monitored process itself are always permitted.
+func verifyProcesses(log *logrus.Logger, processName string, allowlist process.Allowlist) (*Report, error) {
+	pids, err := process.GetPIDs(processName)
+	if err != nil {
+		return nil, err
+	}
+	procs, err := process.Namespaced(pids)
+	if err != nil {
+		return nil, err
+	}
+
+	monitored := make(map[int]struct{}, len(pids))
+	for _, pid := range pids {
+		monitored[pid] = struct{}{}
+	}
+	report := NewReport(processName)
+	for _, p := range procs {
+		report.Checked++
+		if _, ok := monitored[p.PID]; ok || allowlist.Allows(p.Exe, p.Cmdline) {
+			continue
+		}
+		log.WithFields(logrus.Fields{"pid": p.PID, "exe": p.Exe, "cmdline": p.Cmdline}).
+			Error("verifyProcesses(): unexpected process")
+		report.add(&IntegrityError{Type: ErrTypeUnexpectedProcess, Path: p.Exe,
+			Actual: fmt.Sprintf("pid=%d cmdline=%s", p.PID, p.Cmdline)})
+	}
+	return report, nil
+}
@@ -0,0 +1,78 @@
+package integritymonitor
+
+import (
+	"context"
+	"sync"
+
+	"github.com/spf13/viper"
+)
+
+// AuditMode reports whether the @procName process is monitored in the audit
+// mode: violations are logged and alerted with the audit marker but the
+// response action is not performed.
+func AuditMode(procName string) bool {
+	if viper.GetBool("audit") {
+		return true
+	}
+	for _, p := range viper.GetStringSlice("audit-processes") {
+		if p == procName {
+			return true
+		}
+	}
+	return false
+}
+
+// AuditTotal holds the running totals of the response actions which would
+// have been performed for a process in the audit mode.
+type AuditTotal struct {
+	Action     string
+	Actions    int
+	Violations int
+}
+
+var (
+	auditTotalsMu sync.Mutex
+	auditTotals   = make(map[string]AuditTotal)
+)
+
+// AuditTotals returns the would-be action totals keyed by the process name.
+func AuditTotals() map[string]AuditTotal {
+	auditTotalsMu.Lock()
+	defer auditTotalsMu.Unlock()
+	res := make(map[string]AuditTotal, len(auditTotals))
+	for k, v := range auditTotals {
+		res[k] = v
+	}
+	return res
+}
+
+func recordAudit(procName, action string, violations int) AuditTotal {
+	auditTotalsMu.Lock()
+	defer auditTotalsMu.Unlock()
+	t := auditTotals[procName]
+	t.Action = action
+	t.Actions++
+	t.Violations += violations
+	auditTotals[procName] = t
+	return t
+}
+
+// auditResponder records the response action of the wrapped Responder
+// instead of performing it.
+type auditResponder struct {
+	Responder
+	total AuditTotal
+}
+
+func newAuditResponder(r Responder) *auditResponder {
+	return &auditResponder{Responder: r}
+}
+
+func (r *auditResponder) Message(podName string) string {
+	return "[audit] " + r.Responder.Message(podName) + " (not performed)"
+}
+
+func (r *auditResponder) Respond(_ context.Context, report *Report) error {
+	r.total = recordAudit(report.ProcessName, r.Action(), len(report.Violations))
+	return nil
+}
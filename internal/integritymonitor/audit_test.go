@@ -0,0 +1,52 @@
+package integritymonitor
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ScienceSoft-Inc/integrity-sum/pkg/k8s"
+	mockk8s "github.com/ScienceSoft-Inc/integrity-sum/pkg/k8s/mocks"
+)
+
+func TestAuditMode(t *testing.T) {
+	viper.Set("audit-processes", []string{"nginx"})
+	defer func() {
+		viper.Set("audit", nil)
+		viper.Set("audit-processes", nil)
+	}()
+	assert.True(t, AuditMode("nginx"))
+	assert.False(t, AuditMode("redis"))
+
+	viper.Set("audit", true)
+	assert.True(t, AuditMode("redis"))
+}
+
+func TestAuditResponseNotPerformed(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	// the RestartPod call fails the test
+	kc := mockk8s.NewMockIKuberService(ctrl)
+
+	viper.Set("default-response-action", ActionDelete)
+	viper.Set("audit-processes", []string{"audited"})
+	defer func() {
+		viper.Set("default-response-action", nil)
+		viper.Set("audit-processes", nil)
+	}()
+
+	report := NewReport("audited")
+	report.add(&IntegrityError{Type: ErrTypeFileMismatch, Path: "etc/a", Expected: "1", Actual: "2"})
+	report.add(&IntegrityError{Type: ErrTypeNewFile, Path: "etc/b", Actual: "3"})
+	integrityCheckFailed(context.Background(), logrus.New(), report, &k8s.DeploymentData{NamePod: "pod"}, kc)
+	integrityCheckFailed(context.Background(), logrus.New(), report, &k8s.DeploymentData{NamePod: "pod"}, kc)
+
+	assert.Equal(t, AuditTotal{Action: ActionDelete, Actions: 2, Violations: 4}, AuditTotals()["audited"])
+
+	r := newAuditResponder(responders[ActionDelete](kc))
+	assert.Equal(t, "[audit] Restart pod pod (not performed)", r.Message("pod"))
+}
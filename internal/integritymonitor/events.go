@@ -0,0 +1,84 @@
+package integritymonitor
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+
+	"github.com/ScienceSoft-Inc/integrity-sum/pkg/k8s"
+)
+
+// emitViolationEvent creates the Warning event about the @report violations on
+// the pod and its owner if it is enabled by "k8s-events".
+func emitViolationEvent(ctx context.Context, log *logrus.Logger, report *Report, audit bool, kubeClient k8s.IKuberService) {
+	if !viper.GetBool("k8s-events") {
+		return
+	}
+	err := kubeClient.EmitWarningEvent(ctx, k8s.EventReasonIntegrityViolation, violationEventMessage(report, audit))
+	if err != nil {
+		log.WithContext(ctx).WithError(err).Error("failed create violation event")
+	}
+}
+
+// violationEventMessage names the process, the type and the path of every
+// violation of the @report, e.g.
+// "process nginx: file content mismatch /etc/nginx/nginx.conf; new file found /tmp/x".
+func violationEventMessage(report *Report, audit bool) string {
+	violations := make([]string, len(report.Violations))
+	for i, v := range report.Violations {
+		violations[i] = v.Error() + " " + v.Path
+	}
+	msg := fmt.Sprintf("process %s: %s", report.ProcessName, strings.Join(violations, "; "))
+	if audit {
+		msg = "[audit] " + msg
+	}
+	return msg
+}
+
+// annotateScanResult stores the time and the status of the last scan of the
+// process in the pod annotations if it is enabled by "scan-annotations".
+func annotateScanResult(ctx context.Context, log *logrus.Logger, res ScanResult, kubeClient k8s.IKuberService) {
+	if !viper.GetBool("scan-annotations") {
+		return
+	}
+	scanned := res.Started.UTC().Format(time.RFC3339)
+	err := kubeClient.AnnotatePod(ctx, map[string]*string{
+		k8s.LastScanAnnotation(res.Process):       &scanned,
+		k8s.LastScanResultAnnotation(res.Process): &res.Status,
+	})
+	if err != nil {
+		log.WithContext(ctx).WithError(err).WithField("process", res.Process).Error("failed annotate scan result")
+	}
+}
+
+// storeIntegrityReport stores the result of the last scan of the process in
+// its IntegrityReport CR if it is enabled by "integrity-reports".
+func storeIntegrityReport(ctx context.Context, log *logrus.Logger, res ScanResult, kubeClient k8s.IKuberService) {
+	if !viper.GetBool("integrity-reports") {
+		return
+	}
+	report := k8s.IntegrityReport{
+		Process:   res.Process,
+		Image:     viper.GetStringMapString("process-image")[res.Process],
+		Algorithm: viper.GetString("algorithm"),
+		Result:    res.Status,
+		Time:      res.Started,
+		Audit:     res.Audit,
+		Error:     res.Error,
+	}
+	for _, v := range res.Violations {
+		report.Violations = append(report.Violations, k8s.ReportViolation{
+			Reason:   v.Error(),
+			Path:     v.Path,
+			Expected: v.Expected,
+			Actual:   v.Actual,
+		})
+	}
+	if err := kubeClient.UpdateIntegrityReport(ctx, report); err != nil {
+		log.WithContext(ctx).WithError(err).WithField("process", res.Process).Error("failed update integrity report")
+	}
+}
@@ -0,0 +1,96 @@
+package integritymonitor
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ScienceSoft-Inc/integrity-sum/pkg/k8s"
+	mockk8s "github.com/ScienceSoft-Inc/integrity-sum/pkg/k8s/mocks"
+)
+
+func TestViolationEventMessage(t *testing.T) {
+	report := NewReport("nginx")
+	report.add(&IntegrityError{Type: ErrTypeFileMismatch, Path: "etc/nginx/nginx.conf"})
+	assert.Equal(t, "process nginx: file content mismatch etc/nginx/nginx.conf", violationEventMessage(report, false))
+
+	report.add(&IntegrityError{Type: ErrTypeNewFile, Path: "tmp/x"})
+	assert.Equal(t, "[audit] process nginx: file content mismatch etc/nginx/nginx.conf; new file found tmp/x",
+		violationEventMessage(report, true))
+}
+
+func TestEmitViolationEvent(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	kc := mockk8s.NewMockIKuberService(ctrl)
+
+	report := NewReport("nginx")
+	report.add(&IntegrityError{Type: ErrTypeFileDeleted, Path: "etc/a"})
+
+	// disabled, the unexpected call fails the test
+	emitViolationEvent(context.Background(), logrus.New(), report, false, kc)
+
+	viper.Set("k8s-events", true)
+	defer viper.Set("k8s-events", nil)
+	kc.EXPECT().EmitWarningEvent(gomock.Any(), k8s.EventReasonIntegrityViolation, "process nginx: file deleted etc/a").
+		Return(errors.New("forbidden"))
+	emitViolationEvent(context.Background(), logrus.New(), report, false, kc)
+}
+
+func TestAnnotateScanResult(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	kc := mockk8s.NewMockIKuberService(ctrl)
+
+	viper.Set("scan-annotations", true)
+	defer viper.Set("scan-annotations", nil)
+
+	started := time.Date(2023, 5, 4, 10, 20, 30, 0, time.FixedZone("CET", 3600))
+	kc.EXPECT().AnnotatePod(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, annotations map[string]*string) error {
+			assert.Len(t, annotations, 2)
+			assert.Equal(t, "2023-05-04T09:20:30Z", *annotations["nginx.integrity-monitor.scnsoft.com/last-scan"])
+			assert.Equal(t, ScanViolation, *annotations["nginx.integrity-monitor.scnsoft.com/last-scan-result"])
+			return nil
+		})
+	annotateScanResult(context.Background(), logrus.New(), ScanResult{Process: "nginx", Started: started, Status: ScanViolation}, kc)
+}
+
+func TestStoreIntegrityReport(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	kc := mockk8s.NewMockIKuberService(ctrl)
+
+	viper.Set("integrity-reports", true)
+	viper.Set("algorithm", "SHA256")
+	viper.Set("process-image", map[string]string{"nginx": "nginx:1.25"})
+	defer func() {
+		viper.Set("integrity-reports", nil)
+		viper.Set("algorithm", nil)
+		viper.Set("process-image", nil)
+	}()
+
+	started := time.Now()
+	kc.EXPECT().UpdateIntegrityReport(gomock.Any(), k8s.IntegrityReport{
+		Process:   "nginx",
+		Image:     "nginx:1.25",
+		Algorithm: "SHA256",
+		Result:    ScanViolation,
+		Time:      started,
+		Violations: []k8s.ReportViolation{
+			{Reason: IntegrityMessageFileMismatch, Path: "etc/a", Expected: "1", Actual: "2"},
+		},
+	}).Return(nil)
+	storeIntegrityReport(context.Background(), logrus.New(), ScanResult{
+		Process:    "nginx",
+		Started:    started,
+		Status:     ScanViolation,
+		Violations: []*IntegrityError{{Type: ErrTypeFileMismatch, Path: "etc/a", Expected: "1", Actual: "2"}},
+	}, kc)
+}
@@ -0,0 +1,155 @@
+package integritymonitor
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+
+	"github.com/ScienceSoft-Inc/integrity-sum/internal/utils/process"
+	"github.com/ScienceSoft-Inc/integrity-sum/pkg/minio"
+)
+
+const evidenceTimeLayout = "20060102T150405.000Z"
+
+// Evidence describes the integrity violations of a process. It is stored as
+// <namespace>/<pod>/<time>/evidence.json in the evidence bucket along with the
+// copies of the violating files under <namespace>/<pod>/<time>/files/.
+type Evidence struct {
+	Time       time.Time      `json:"time"`
+	Namespace  string         `json:"namespace"`
+	Pod        string         `json:"pod"`
+	Image      string         `json:"image"`
+	Process    string         `json:"process"`
+	Processes  []process.Info `json:"processes"`
+	Violations []EvidenceFile `json:"violations"`
+}
+
+// EvidenceFile is a single violation with the reference to the copy of the
+// violating file and its SHA-256 hash. Note explains why the file has not been
+// copied.
+type EvidenceFile struct {
+	Path     string `json:"path"`
+	Reason   string `json:"reason"`
+	Expected string `json:"expected,omitempty"`
+	Actual   string `json:"actual,omitempty"`
+	Object   string `json:"object,omitempty"`
+	SHA256   string `json:"sha256,omitempty"`
+	Note     string `json:"note,omitempty"`
+}
+
+// saveFunc uploads @size bytes of the @r reader as the @object.
+type saveFunc func(object string, r io.Reader, size int64) error
+
+// captureEvidence copies the violating files of the @report into the
+// "evidence-bucket" bucket along with the violation details and the process
+// metadata. Returns the reference <bucket>/<object> to the evidence manifest.
+func captureEvidence(ctx context.Context, log *logrus.Logger, report *Report, podName string) (string, error) {
+	bucket := viper.GetString("evidence-bucket")
+	now := time.Now().UTC()
+	ns := viper.GetString("pod-namespace")
+	prefix := path.Join(ns, podName, now.Format(evidenceTimeLayout))
+
+	ms := minio.Instance()
+	if err := ms.CreateBucketIfNotExists(ctx, bucket); err != nil {
+		return "", err
+	}
+	save := func(object string, r io.Reader, size int64) error {
+		return ms.SaveReader(ctx, bucket, object, r, size)
+	}
+	evidence := collectEvidence(log, report, prefix, viper.GetInt64("evidence-max-file-size"), save)
+	evidence.Time = now
+	evidence.Namespace = ns
+	evidence.Pod = podName
+	evidence.Image = viper.GetStringMapString("process-image")[report.ProcessName]
+	if pids, err := process.GetPIDs(report.ProcessName); err == nil {
+		for _, pid := range pids {
+			if info, err := process.Describe(pid); err == nil {
+				evidence.Processes = append(evidence.Processes, info)
+			}
+		}
+	}
+
+	manifest, err := json.MarshalIndent(evidence, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	object := prefix + "/evidence.json"
+	if err := ms.Save(ctx, bucket, object, manifest); err != nil {
+		return "", fmt.Errorf("failed save evidence: %w", err)
+	}
+
+	ref := bucket + "/" + object
+	log.WithFields(logrus.Fields{"process": report.ProcessName, "evidence": ref}).Info("evidence captured")
+	return ref, nil
+}
+
+// collectEvidence uploads the violating files of the @report up to @maxSize
+// bytes one by one with @save as the objects under the @prefix. The files are
+// read from the file system view of the instance they violate in. Returns the
+// evidence with the object names and the hashes of the copies.
+func collectEvidence(log *logrus.Logger, report *Report, prefix string, maxSize int64, save saveFunc) *Evidence {
+	evidence := &Evidence{Process: report.ProcessName}
+	for _, v := range report.Violations {
+		f := EvidenceFile{Path: v.Path, Reason: v.Error(), Expected: v.Expected, Actual: v.Actual}
+		if hasEvidenceFile(v.Type) {
+			object := prefix + "/files/" + strings.TrimPrefix(v.Path, "/")
+			hash, err := copyEvidenceFile(report.RootOf(v), v.Path, maxSize, object, save)
+			if err != nil {
+				log.WithError(err).WithField("path", v.Path).Warn("failed copy evidence")
+				f.Note = err.Error()
+			} else {
+				f.Object = object
+				f.SHA256 = hash
+			}
+		}
+		evidence.Violations = append(evidence.Violations, f)
+	}
+	return evidence
+}
+
+// hasEvidenceFile reports whether the violation of the @errType type has the
+// file content worth copying.
+func hasEvidenceFile(errType int) bool {
+	switch errType {
+	case ErrTypeFileMismatch, ErrTypeNewFile, ErrTypeMetadataMismatch, ErrTypeOversizeFile, ErrTypeUnknownExecutable:
+		return true
+	}
+	return false
+}
+
+// copyEvidenceFile streams the regular file @p in the @root file system view
+// to the @object with @save, returns the SHA-256 hash of the copy. The @p is
+// resolved inside the @root without following symlinks, so a tampered path can
+// not expose the files of the sidecar.
+func copyEvidenceFile(root, p string, maxSize int64, object string, save saveFunc) (string, error) {
+	if root == "" {
+		return "", fmt.Errorf("file system of the process is unknown")
+	}
+	f, err := openFileInRoot(root, p)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	fi, err := f.Stat()
+	if err != nil {
+		return "", err
+	}
+	if maxSize > 0 && fi.Size() > maxSize {
+		return "", fmt.Errorf("file size %d exceeds the evidence limit %d", fi.Size(), maxSize)
+	}
+
+	h := sha256.New()
+	if err := save(object, io.TeeReader(io.LimitReader(f, fi.Size()), h), fi.Size()); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
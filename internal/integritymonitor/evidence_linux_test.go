@@ -0,0 +1,73 @@
+package integritymonitor
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCollectEvidence(t *testing.T) {
+	root := t.TempDir() + "/"
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "etc"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "etc", "app.conf"), []byte("tampered"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "etc", "big.bin"), make([]byte, 64), 0o644))
+	require.NoError(t, os.Symlink("/etc/passwd", filepath.Join(root, "etc", "link")))
+	// the directory swapped for a symlink leads to the files of the sidecar
+	secrets := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(secrets, "token"), []byte("secret"), 0o600))
+	require.NoError(t, os.Symlink(secrets, filepath.Join(root, "secrets")))
+
+	report := NewReport("app")
+	report.Root = root
+	report.add(&IntegrityError{Type: ErrTypeFileMismatch, Path: "/etc/app.conf", Expected: "1", Actual: "2"})
+	report.add(&IntegrityError{Type: ErrTypeNewFile, Path: "etc/big.bin", Actual: "3"})
+	report.add(&IntegrityError{Type: ErrTypeNewFile, Path: "etc/link", Actual: "4"})
+	report.add(&IntegrityError{Type: ErrTypeFileDeleted, Path: "etc/gone", Expected: "5"})
+	report.add(&IntegrityError{Type: ErrTypeFileMismatch, Path: "secrets/token", Expected: "7", Actual: "8"})
+
+	other := t.TempDir() + "/"
+	require.NoError(t, os.MkdirAll(filepath.Join(other, "bin"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(other, "bin", "app"), []byte("other"), 0o755))
+	report.add(&IntegrityError{Type: ErrTypeUnknownExecutable, Path: "bin/app", Actual: "6", Root: other})
+
+	saved := make(map[string][]byte)
+	save := func(object string, r io.Reader, size int64) error {
+		data, err := io.ReadAll(r)
+		require.NoError(t, err)
+		assert.EqualValues(t, size, len(data))
+		saved[object] = data
+		return nil
+	}
+	evidence := collectEvidence(logrus.New(), report, "ns/pod/t", 32, save)
+	assert.Equal(t, "app", evidence.Process)
+	require.Len(t, evidence.Violations, 6)
+
+	sum := sha256.Sum256([]byte("tampered"))
+	assert.Equal(t, EvidenceFile{Path: "/etc/app.conf", Reason: IntegrityMessageFileMismatch, Expected: "1", Actual: "2",
+		Object: "ns/pod/t/files/etc/app.conf", SHA256: hex.EncodeToString(sum[:])}, evidence.Violations[0])
+
+	assert.Empty(t, evidence.Violations[1].Object)
+	assert.Contains(t, evidence.Violations[1].Note, "exceeds the evidence limit")
+	assert.Empty(t, evidence.Violations[2].Object)
+	assert.Contains(t, evidence.Violations[2].Note, "too many levels of symbolic links")
+	assert.Equal(t, EvidenceFile{Path: "etc/gone", Reason: IntegrityMessageFileDeleted, Expected: "5"}, evidence.Violations[3])
+
+	assert.Empty(t, evidence.Violations[4].Object)
+	assert.Contains(t, evidence.Violations[4].Note, "too many levels of symbolic links")
+
+	// the executable is read from the instance it violates in
+	sum = sha256.Sum256([]byte("other"))
+	assert.Equal(t, "ns/pod/t/files/bin/app", evidence.Violations[5].Object)
+	assert.Equal(t, hex.EncodeToString(sum[:]), evidence.Violations[5].SHA256)
+	assert.Equal(t, map[string][]byte{
+		"ns/pod/t/files/etc/app.conf": []byte("tampered"),
+		"ns/pod/t/files/bin/app":      []byte("other"),
+	}, saved)
+}
@@ -0,0 +1,111 @@
+package integritymonitor
+
+import (
+	"context"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+
+	"github.com/ScienceSoft-Inc/integrity-sum/internal/data"
+	"github.com/ScienceSoft-Inc/integrity-sum/internal/utils/process"
+	"github.com/ScienceSoft-Inc/integrity-sum/internal/worker"
+)
+
+// verifyExecutables verifies the executables and the executable file-backed
+// mappings (e.g. shared libraries) of every @processName instance against the
+// snapshot. Running executables which have been deleted or are missing from
+// the snapshot are violations.
+func verifyExecutables(ctx context.Context, log *logrus.Logger, processName string,
+	cache *worker.StatCache) (*Report, error) {
+	pids, err := process.GetPIDs(processName)
+	if err != nil {
+		return nil, err
+	}
+	algName := viper.GetString("algorithm")
+	expected, err := loadExpectedHashes(ctx, log, processName, algName)
+	if err != nil {
+		return nil, err
+	}
+	report := compareExecutables(ctx, log, processName, pids, expected, cache)
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	return report, nil
+}
+
+func compareExecutables(ctx context.Context, log *logrus.Logger, processName string, pids []int,
+	expected map[string]*data.HashDataOutput, cache *worker.StatCache) *Report {
+	report := NewReport(processName)
+	// file path in the /proc/<pid>/root -> path in the snapshot
+	files := make(map[string]string)
+	// file path in the /proc/<pid>/root -> the root
+	roots := make(map[string]string)
+	seen := make(map[string]struct{})
+	for _, pid := range pids {
+		exes, err := process.Executables(pid)
+		if err != nil {
+			// the process might exit meanwhile
+			log.WithError(err).WithField("pid", pid).Warn("failed read process executables")
+			continue
+		}
+		root := process.Root(pid)
+		if report.Root == "" {
+			report.Root = root
+		}
+		// processes sharing the mount namespace see the same files
+		ns, err := process.MountNamespace(pid)
+		if err != nil {
+			ns = root
+		}
+		for _, e := range exes {
+			if _, ok := seen[ns+e.Path]; ok {
+				continue
+			}
+			seen[ns+e.Path] = struct{}{}
+
+			path := strings.TrimPrefix(e.Path, "/")
+			if e.Deleted {
+				report.Checked++
+				log.WithFields(logrus.Fields{"file": path, "pid": pid}).Error("verifyExecutables(): deleted executable running")
+				exp := ""
+				if h, ok := expected[path]; ok {
+					exp = h.Hash
+				}
+				report.add(&IntegrityError{Type: ErrTypeDeletedExecutable, Path: path, Expected: exp, Root: root})
+				continue
+			}
+			files[root+path] = path
+			roots[root+path] = root
+		}
+	}
+
+	fileNameC := make(chan string, len(files))
+	for f := range files {
+		fileNameC <- f
+	}
+	close(fileNameC)
+	hashC := worker.WorkersPool(viper.GetInt("count-workers"), fileNameC,
+		worker.NewWorker(ctx, viper.GetString("algorithm"), log, workerOptions(
+			worker.WithStatCache(cache),
+			worker.WithXattrsFilter(func(p string) bool {
+				h, ok := expected[files[p]]
+				return ok && h.Meta != nil
+			}),
+		)...))
+	for v := range hashC {
+		path := files[v.Path]
+		report.Checked++
+		h, ok := expected[path]
+		if !ok {
+			log.WithField("file", path).Error("verifyExecutables(): executable is not in the snapshot")
+			report.add(&IntegrityError{Type: ErrTypeUnknownExecutable, Path: path, Actual: v.Hash, Root: roots[v.Path]})
+			continue
+		}
+		for _, e := range verifyFile(log, path, h, v) {
+			e.Root = roots[v.Path]
+			report.add(e)
+		}
+	}
+	return report
+}
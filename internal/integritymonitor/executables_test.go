@@ -0,0 +1,65 @@
+package integritymonitor
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ScienceSoft-Inc/integrity-sum/internal/data"
+	"github.com/ScienceSoft-Inc/integrity-sum/pkg/hasher"
+)
+
+func TestCompareExecutables(t *testing.T) {
+	procDir := t.TempDir()
+	pidDir := filepath.Join(procDir, "10")
+	files := map[string]string{
+		"app/bin/app":     "app",
+		"usr/lib/libc.so": "libc",
+		"tmp/evil.so":     "evil",
+	}
+	for p, content := range files {
+		path := filepath.Join(pidDir, "root", p)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o755))
+	}
+	require.NoError(t, os.Symlink("/app/bin/app", filepath.Join(pidDir, "exe")))
+	maps := `55d0c0a21000-55d0c0b21000 r-xp 00021000 08:01 1311 /app/bin/app
+7f1c2d000000-7f1c2d195000 r-xp 00028000 08:01 2201 /usr/lib/libc.so
+7f1c2d200000-7f1c2d201000 r-xp 00000000 08:01 2202 /tmp/evil.so
+7f1c2d300000-7f1c2d301000 r-xp 00000000 08:01 2203 /usr/lib/old.so (deleted)
+`
+	require.NoError(t, os.WriteFile(filepath.Join(pidDir, "maps"), []byte(maps), 0o644))
+
+	viper.Set("proc-dir", procDir)
+	viper.Set("count-workers", 2)
+	defer func() {
+		viper.Set("proc-dir", "")
+		viper.Set("count-workers", 0)
+	}()
+
+	hash := func(p string) string {
+		h, err := hasher.NewFileHasher("sha256", logrus.New()).HashFile(filepath.Join(pidDir, "root", p))
+		require.NoError(t, err)
+		return h
+	}
+	expected := map[string]*data.HashDataOutput{
+		"app/bin/app":     {Hash: hash("app/bin/app")},
+		"usr/lib/libc.so": {Hash: "tampered"},
+		"usr/lib/old.so":  {Hash: "1"},
+	}
+
+	report := compareExecutables(context.Background(), logrus.New(), "app", []int{10}, expected, nil)
+	assert.Equal(t, 4, report.Checked)
+	root := pidDir + "/root/"
+	assert.ElementsMatch(t, []*IntegrityError{
+		{Type: ErrTypeDeletedExecutable, Path: "usr/lib/old.so", Expected: "1", Root: root},
+		{Type: ErrTypeFileMismatch, Path: "usr/lib/libc.so", Expected: "tampered", Actual: hash("usr/lib/libc.so"), Root: root},
+		{Type: ErrTypeUnknownExecutable, Path: "tmp/evil.so", Actual: hash("tmp/evil.so"), Root: root},
+	}, report.Violations)
+}
@@ -0,0 +1,110 @@
+package integritymonitor
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+
+	"github.com/ScienceSoft-Inc/integrity-sum/internal/data"
+	"github.com/ScienceSoft-Inc/integrity-sum/internal/worker"
+	"github.com/ScienceSoft-Inc/integrity-sum/pkg/hasher"
+	"github.com/ScienceSoft-Inc/integrity-sum/pkg/minio"
+)
+
+// errGoldenChanged is returned when the file does not match its hash anymore
+var errGoldenChanged = errors.New("file content does not match its hash")
+
+// GoldenObject returns the name of the golden copy of the file with the @hash
+// calculated with the @alg algorithm, the golden store is content-addressed.
+func GoldenObject(alg, hash string) string {
+	return strings.ToLower(alg) + "/" + hash
+}
+
+// uploadGoldenCopies uploads the regular files of the @hashes found in the
+// @rootPath into the "golden-bucket" bucket unless their copies are already
+// stored. The files hashed partially due to the size limit and the files
+// changed since they were hashed are skipped.
+func uploadGoldenCopies(ctx context.Context, log *logrus.Logger, rootPath string, hashes []worker.FileHash, alg string) error {
+	bucket := viper.GetString("golden-bucket")
+	ms := minio.Instance()
+	if err := ms.CreateBucketIfNotExists(ctx, bucket); err != nil {
+		return err
+	}
+
+	uploaded, skipped := 0, 0
+	seen := make(map[string]struct{}, len(hashes))
+	for _, h := range hashes {
+		if h.Type != "" || h.Hash == data.NoHash || h.Oversize != "" {
+			continue
+		}
+		object := GoldenObject(alg, h.Hash)
+		if _, ok := seen[object]; ok {
+			continue
+		}
+		seen[object] = struct{}{}
+
+		exists, err := ms.Exists(ctx, bucket, object)
+		if err != nil {
+			return err
+		}
+		if exists {
+			continue
+		}
+		save := func(object string, r io.Reader, size int64) error {
+			return ms.SaveReader(ctx, bucket, object, r, size)
+		}
+		if err := storeGoldenCopy(rootPath, h.Path, h.Hash, alg, object, save); err != nil {
+			if errors.Is(err, errGoldenChanged) {
+				log.WithField("path", h.Path).Warn("golden copy skipped, the file has changed since it was hashed")
+				skipped++
+				continue
+			}
+			return err
+		}
+		uploaded++
+	}
+	log.WithFields(logrus.Fields{"bucket": bucket, "uploaded": uploaded, "skipped": skipped, "files": len(seen)}).Info("golden copies stored")
+	return nil
+}
+
+// storeGoldenCopy spools the regular file @p of the @root file system view
+// into a temporary file and uploads it with @save as the @object only if its
+// content matches the @hash calculated with the @alg algorithm. The @p is
+// resolved inside the @root without following symlinks, so a tampered path can
+// not substitute the files of the sidecar for the golden copy.
+func storeGoldenCopy(root, p, hash, alg, object string, save saveFunc) error {
+	f, err := openFileInRoot(root, p)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	spool, err := os.CreateTemp("", "golden-")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(spool.Name())
+	defer spool.Close()
+
+	// the copy is hashed as it is spooled, the file may be changed meanwhile
+	actual, err := hasher.NewFileHasher(alg, logrus.StandardLogger()).HashData(io.TeeReader(f, spool))
+	if err != nil {
+		return err
+	}
+	if actual != hash {
+		return errGoldenChanged
+	}
+	size, err := spool.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return err
+	}
+	if _, err := spool.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	return save(object, spool, size)
+}
@@ -0,0 +1,48 @@
+package integritymonitor
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ScienceSoft-Inc/integrity-sum/pkg/hasher"
+)
+
+func TestStoreGoldenCopy(t *testing.T) {
+	root := t.TempDir() + "/"
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "etc"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "etc", "app.conf"), []byte("original"), 0o644))
+	// the directory swapped for a symlink leads to the files of the sidecar
+	secrets := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(secrets, "token"), []byte("secret"), 0o600))
+	require.NoError(t, os.Symlink(secrets, filepath.Join(root, "secrets")))
+	require.NoError(t, os.Symlink("/etc/passwd", filepath.Join(root, "etc", "link")))
+
+	hashOf := func(s string) string {
+		h, err := hasher.NewFileHasher("sha256", logrus.New()).HashData(strings.NewReader(s))
+		require.NoError(t, err)
+		return h
+	}
+	saved := make(map[string]string)
+	save := func(object string, r io.Reader, size int64) error {
+		data, err := io.ReadAll(r)
+		require.NoError(t, err)
+		assert.EqualValues(t, size, len(data))
+		saved[object] = string(data)
+		return nil
+	}
+
+	require.NoError(t, storeGoldenCopy(root, "/etc/app.conf", hashOf("original"), "sha256", "sha256/original", save))
+	assert.ErrorIs(t, storeGoldenCopy(root, "etc/app.conf", hashOf("learned"), "sha256", "sha256/learned", save), errGoldenChanged)
+	assert.ErrorContains(t, storeGoldenCopy(root, "secrets/token", hashOf("secret"), "sha256", "sha256/secret", save),
+		"too many levels of symbolic links")
+	assert.ErrorContains(t, storeGoldenCopy(root, "etc/link", hashOf("link"), "sha256", "sha256/link", save),
+		"too many levels of symbolic links")
+	assert.Equal(t, map[string]string{"sha256/original": "original"}, saved)
+}
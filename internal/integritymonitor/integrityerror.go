@@ -1,15 +1,35 @@
 package integritymonitor
 
+import "encoding/json"
+
 const (
 	ErrTypeFileMismatch int = iota + 1
 	ErrTypeNewFile
 	ErrTypeFileDeleted
+	ErrTypeMetadataMismatch
+	ErrTypeSymlinkMismatch
+	ErrTypeNewSpecialFile
+	ErrTypeDirAdded
+	ErrTypeDirDeleted
+	ErrTypeOversizeFile
+	ErrTypeDeletedExecutable
+	ErrTypeUnknownExecutable
+	ErrTypeUnexpectedProcess
+	ErrTypeUnexpectedListener
 )
 
+// IntegrityError describes a single integrity violation. Expected is the hash
+// stored in the snapshot, Actual is the hash calculated during the scan, any
+// of them is empty if it does not exist (e.g. for new or deleted files). For
+// the metadata mismatch they hold the differing metadata attributes.
 type IntegrityError struct {
-	Type int
-	Path string
-	Hash string
+	Type     int
+	Path     string
+	Expected string
+	Actual   string
+	// Root is the file system view the file was found in if it differs from
+	// the Root of the report, e.g. for the executables of several instances
+	Root string
 }
 
 func (e *IntegrityError) Error() string {
@@ -20,6 +40,36 @@ func (e *IntegrityError) Error() string {
 		return IntegrityMessageFileDeleted
 	case ErrTypeFileMismatch:
 		return IntegrityMessageFileMismatch
+	case ErrTypeMetadataMismatch:
+		return IntegrityMessageMetadataMismatch
+	case ErrTypeSymlinkMismatch:
+		return IntegrityMessageSymlinkMismatch
+	case ErrTypeNewSpecialFile:
+		return IntegrityMessageNewSpecialFile
+	case ErrTypeDirAdded:
+		return IntegrityMessageDirAdded
+	case ErrTypeDirDeleted:
+		return IntegrityMessageDirDeleted
+	case ErrTypeOversizeFile:
+		return IntegrityMessageOversizeFile
+	case ErrTypeDeletedExecutable:
+		return IntegrityMessageDeletedExecutable
+	case ErrTypeUnknownExecutable:
+		return IntegrityMessageUnknownExecutable
+	case ErrTypeUnexpectedProcess:
+		return IntegrityMessageUnexpectedProcess
+	case ErrTypeUnexpectedListener:
+		return IntegrityMessageUnexpectedListener
 	}
 	return IntegrityMessageUnknownErr
 }
+
+// MarshalJSON encodes the violation with its reason instead of the type code.
+func (e *IntegrityError) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Reason   string `json:"reason"`
+		Path     string `json:"path"`
+		Expected string `json:"expected,omitempty"`
+		Actual   string `json:"actual,omitempty"`
+	}{e.Error(), e.Path, e.Expected, e.Actual})
+}
@@ -3,13 +3,17 @@ package integritymonitor
 import (
 	"bytes"
 	"context"
-	"errors"
 	"fmt"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/viper"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
 
 	"github.com/ScienceSoft-Inc/integrity-sum/internal/data"
 	"github.com/ScienceSoft-Inc/integrity-sum/internal/utils/process"
@@ -17,165 +21,567 @@ import (
 	"github.com/ScienceSoft-Inc/integrity-sum/internal/worker"
 	"github.com/ScienceSoft-Inc/integrity-sum/pkg/alerts"
 	"github.com/ScienceSoft-Inc/integrity-sum/pkg/k8s"
+	"github.com/ScienceSoft-Inc/integrity-sum/pkg/metrics"
 	"github.com/ScienceSoft-Inc/integrity-sum/pkg/minio"
+	"github.com/ScienceSoft-Inc/integrity-sum/pkg/tracing"
 )
 
 const (
-	IntegrityMessageNewFileFound = "new file found"
-	IntegrityMessageFileDeleted  = "file deleted"
-	IntegrityMessageFileMismatch = "file content mismatch"
-	IntegrityMessageUnknownErr   = "unknown integrity error"
+	IntegrityMessageNewFileFound       = "new file found"
+	IntegrityMessageFileDeleted        = "file deleted"
+	IntegrityMessageFileMismatch       = "file content mismatch"
+	IntegrityMessageMultipleErrs       = "multiple integrity violations"
+	IntegrityMessageMetadataMismatch   = "file metadata mismatch"
+	IntegrityMessageSymlinkMismatch    = "symlink target changed"
+	IntegrityMessageNewSpecialFile     = "new special file found"
+	IntegrityMessageDirAdded           = "directory added"
+	IntegrityMessageDirDeleted         = "directory deleted"
+	IntegrityMessageOversizeFile       = "file size limit exceeded"
+	IntegrityMessageDeletedExecutable  = "deleted executable running"
+	IntegrityMessageUnknownExecutable  = "executable not in snapshot"
+	IntegrityMessageUnexpectedProcess  = "unexpected process running"
+	IntegrityMessageUnexpectedListener = "unexpected listening socket"
+	IntegrityMessageBaselineLearned    = "baseline learned"
+	IntegrityMessageBaselineUpdated    = "baseline updated"
+	IntegrityMessageFilesRestored      = "files restored"
+	IntegrityMessageRestoreFailed      = "files restore failed"
+	IntegrityMessageResponseFailed     = "response action failed"
+	IntegrityMessageUnknownErr         = "unknown integrity error"
 )
 
-func GetProcessPath(procName string, path string) (string, error) {
-	pid, err := process.GetPID(procName)
-	if err != nil {
-		return "", fmt.Errorf("failed build process path: %w", err)
+var (
+	throttleOnce sync.Once
+	throttle     *worker.Throttle
+)
+
+// workerOptions returns the @opts along with the configured file size limit,
+// throttling and priority of the workers. The throttle is shared by all scans.
+func workerOptions(opts ...worker.Option) []worker.Option {
+	throttleOnce.Do(func() {
+		throttle = worker.NewThrottle(viper.GetInt64("max-bytes-per-sec"), viper.GetInt("max-files-per-sec"))
+	})
+	opts = append(opts,
+		worker.WithSizeLimit(viper.GetInt64("max-file-size"), viper.GetString("oversize-policy")),
+		worker.WithThrottle(throttle),
+	)
+	if viper.GetBool("low-priority") {
+		opts = append(opts, worker.WithLowPriority())
+	}
+	return opts
+}
+
+// ValidateSizeLimit checks the configured policy for the files exceeding the
+// size limit.
+func ValidateSizeLimit() error {
+	policy := viper.GetString("oversize-policy")
+	for _, p := range worker.OversizePolicies {
+		if p == policy {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown oversize policy %q, available: %s", policy, strings.Join(worker.OversizePolicies, ", "))
+}
+
+var (
+	statCachesMu sync.Mutex
+	statCaches   = make(map[string]*worker.StatCache)
+)
+
+// processStatCache returns the stat cache of the @procName process.
+func processStatCache(procName string) *worker.StatCache {
+	statCachesMu.Lock()
+	defer statCachesMu.Unlock()
+	c, ok := statCaches[procName]
+	if !ok {
+		c = worker.NewStatCache()
+		statCaches[procName] = c
 	}
-	return fmt.Sprintf("/proc/%d/root/%s", pid, path), nil
+	return c
 }
 
+// CheckIntegrity verifies the files, executables, processes and listening
+// sockets of the @processName process and records the result of the scan.
 func CheckIntegrity(ctx context.Context, log *logrus.Logger, processName string, monitoringDirectories []string,
-	deploymentData *k8s.DeploymentData, kubeClient *k8s.KubeClient) error {
-	log.Debug("begin check integrity")
+	deploymentData *k8s.DeploymentData, kubeClient k8s.IKuberService) error {
+	ctx, span := tracing.Start(ctx, "CheckIntegrity", attribute.String("process", processName))
+	defer span.End()
+
+	started := time.Now()
+	err := checkIntegrity(ctx, log, processName, monitoringDirectories, deploymentData, kubeClient)
+	if ctx.Err() == nil {
+		res := recordResult(processName, started, err)
+		metrics.ScanDuration.WithLabelValues(processName, res.Status).Observe(time.Since(started).Seconds())
+		span.SetAttributes(attribute.String("scan.status", res.Status))
+		annotateScanResult(ctx, log, res, kubeClient)
+		storeIntegrityReport(ctx, log, res, kubeClient)
+	}
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	return err
+}
+
+func checkIntegrity(ctx context.Context, log *logrus.Logger, processName string, monitoringDirectories []string,
+	deploymentData *k8s.DeploymentData, kubeClient k8s.IKuberService) error {
+	log.WithContext(ctx).Debug("begin check integrity")
+	unlock := lockProcess(processName)
+	defer unlock()
+
+	roots, err := process.Roots(processName)
+	if err != nil {
+		log.WithError(err).Error("failed build process path")
+		return err
+	}
+
+	skip, err := learnBaseline(ctx, log, processName, roots, monitoringDirectories, deploymentData, kubeClient)
+	if err != nil {
+		log.WithError(err).Error("failed learn baseline")
+		return err
+	}
+	if skip {
+		return nil
+	}
+
+	var cache *worker.StatCache
+	if viper.GetBool("stat-cache") {
+		cache = processStatCache(processName)
+		if cache.Begin(viper.GetInt("full-rehash-cycles")) {
+			log.WithField("process", processName).Info("full rehash cycle, stat cache is not used")
+		}
+	}
+
+	// every instance of the process is verified
+	for _, root := range roots {
+		report, err := scanRoot(ctx, log, processName, root, monitoringDirectories, cache)
+		if err != nil {
+			return err
+		}
+		if report.HasViolations() {
+			integrityCheckFailed(ctx, log, report, deploymentData, kubeClient)
+			return report
+		}
+		log.WithContext(ctx).WithFields(logrus.Fields{"root": root, "countHashes": report.Checked}).Info("hashes compared successfully")
+	}
+
+	if viper.GetBool("verify-executables") {
+		report, err := verifyExecutables(ctx, log, processName, cache)
+		if err != nil {
+			log.WithError(err).Error("failed verify executables")
+			return err
+		}
+		if report.HasViolations() {
+			integrityCheckFailed(ctx, log, report, deploymentData, kubeClient)
+			return report
+		}
+		log.WithField("countExecutables", report.Checked).Info("executables verified successfully")
+	}
+
+	allowlist, err := processAllowlist(processName)
+	if err != nil {
+		return err
+	}
+	if allowlist != nil {
+		report, err := verifyProcesses(log, processName, allowlist)
+		if err != nil {
+			log.WithError(err).Error("failed verify processes")
+			return err
+		}
+		if report.HasViolations() {
+			integrityCheckFailed(ctx, log, report, deploymentData, kubeClient)
+			return report
+		}
+		log.WithField("countProcesses", report.Checked).Info("processes verified successfully")
+	}
 
+	if viper.GetBool("verify-listeners") {
+		report, err := verifyListeners(ctx, log, processName)
+		if err != nil {
+			log.WithError(err).Error("failed verify listeners")
+			return err
+		}
+		if report.HasViolations() {
+			integrityCheckFailed(ctx, log, report, deploymentData, kubeClient)
+			return report
+		}
+		log.WithField("countListeners", report.Checked).Info("listeners verified successfully")
+	}
+	if cache != nil {
+		cache.Prune()
+	}
+	return nil
+}
+
+// scanRoot verifies the @monitoringDirectories in the @root file system view
+// of a process instance against the snapshot of the process.
+func scanRoot(ctx context.Context, log *logrus.Logger, processName, root string, monitoringDirectories []string,
+	cache *worker.StatCache) (_ *Report, err error) {
 	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
-	errC := make(chan error)
-	defer close(errC)
 
-	var err error
 	paths := make([]string, len(monitoringDirectories))
 	for i, p := range monitoringDirectories {
-		paths[i], err = GetProcessPath(processName, p)
-		if err != nil {
-			log.WithError(err).Error("failed build process path")
-			return err
-		}
+		paths[i] = root + p
+	}
+
+	ctx, span := tracing.Start(ctx, "scanRoot", attribute.String("process", processName), attribute.String("root", root))
+	defer func() { tracing.End(span, err) }()
 
+	expected, err := loadExpectedHashes(ctx, log, processName, viper.GetString("algorithm"))
+	if err != nil {
+		log.WithContext(ctx).WithError(err).Error("check integrity failed")
+		return nil, err
 	}
 
-	comparedHashesChan := compareHashes(ctx, log, worker.WorkersPool(
+	reportC := compareHashes(ctx, log, worker.WorkersPool(
 		viper.GetInt("count-workers"),
 		walker.ChanWalkDir(ctx, paths, log),
-		worker.NewWorker(ctx, viper.GetString("algorithm"), log),
-	), processName, viper.GetString("algorithm"), errC)
+		worker.NewWorker(ctx, viper.GetString("algorithm"), log, workerOptions(
+			worker.WithStatCache(cache),
+			worker.WithXattrsFilter(withMetaFilter(root, expected)),
+		)...),
+	), processName, root, expected, viper.GetBool("full-diff"))
 
 	log.Trace("calculate & save hashes...")
 	select {
 	case <-ctx.Done():
 		log.Error(ctx.Err())
-		return ctx.Err()
-	case countHashes := <-comparedHashesChan:
-		log.WithField("countHashes", countHashes).Info("hashes compared successfully")
-		return nil
-	case err := <-errC:
-		integrityCheckFailed(log, err, deploymentData, kubeClient, processName)
-		return err
+		return nil, ctx.Err()
+	case report := <-reportC:
+		if report == nil {
+			return nil, ctx.Err()
+		}
+		return report, nil
 	}
 }
 
+// compareHashes matches the hashes from @hashC against the @expected ones in
+// background. The report is sent once, the channel is buffered so the
+// goroutine never blocks if the scan is canceled meanwhile.
 func compareHashes(
 	ctx context.Context,
 	log *logrus.Logger,
 	hashC <-chan worker.FileHash,
 	procName string,
-	algName string,
-	errC chan<- error) <-chan int {
-
-	doneC := make(chan int)
+	root string,
+	expected map[string]*data.HashDataOutput,
+	fullDiff bool) <-chan *Report {
+	doneC := make(chan *Report, 1)
 	go func() {
 		defer close(doneC)
-
-		procDirs, err := GetProcessPath(procName, "")
-		if err != nil {
-			errC <- fmt.Errorf("failed get process path: %w", err)
+		report := NewReport(procName)
+		report.Root = root
+		if !compareWithExpected(ctx, log, hashC, root, expected, fullDiff, report) {
 			return
 		}
+		doneC <- report
+	}()
+	return doneC
+}
 
-		ms := minio.Instance()
-		csFile, err := process.CheckSumFile(procName, algName)
-		if err != nil {
-			errC <- fmt.Errorf("failed getting check sum file name: %w", err)
-			return
-		}
-		log.Infof("getting check sums file %s", csFile)
-		hashData, err := ms.Load(ctx, viper.GetString("minio-bucket"), csFile)
-		if err != nil {
-			errC <- fmt.Errorf("cannot read hash data: %w", err)
-			return
+// withMetaFilter returns the filter of the files under the @prefix whose
+// @expected records have the metadata, the extended attributes of the other
+// files are not verified. The filter is built in advance since the @expected
+// records are removed while they are verified.
+func withMetaFilter(prefix string, expected map[string]*data.HashDataOutput) func(string) bool {
+	withMeta := make(map[string]struct{})
+	for p, h := range expected {
+		if h.Meta != nil {
+			withMeta[p] = struct{}{}
 		}
+	}
+	return func(path string) bool {
+		_, ok := withMeta[relativePath(prefix, path)]
+		return ok
+	}
+}
 
-		expectedHashes, err := data.NewFileStorage(bytes.NewReader(hashData)).Get()
-		if err != nil {
-			errC <- fmt.Errorf("failed get hash data: %w", err)
-			return
-		}
+// loadExpectedHashes loads the snapshot of the @procName process from the
+// MinIO storage and returns it as a map keyed by the file path.
+func loadExpectedHashes(ctx context.Context, log *logrus.Logger, procName, algName string) (_ map[string]*data.HashDataOutput, err error) {
+	ctx, span := tracing.Start(ctx, "loadExpectedHashes", attribute.String("process", procName))
+	defer func() { tracing.End(span, err) }()
+
+	ms := minio.Instance()
+	csFile, err := process.CheckSumFile(procName, algName)
+	if err != nil {
+		return nil, fmt.Errorf("failed getting check sum file name: %w", err)
+	}
+	span.SetAttributes(attribute.String("file", csFile))
+	log.WithContext(ctx).Infof("getting check sums file %s", csFile)
+	hashData, err := ms.Load(ctx, viper.GetString("minio-bucket"), csFile)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read hash data: %w", err)
+	}
+
+	expectedHashes, err := data.NewFileStorage(bytes.NewReader(hashData)).Get()
+	if err != nil {
+		return nil, fmt.Errorf("failed get hash data: %w", err)
+	}
+
+	//convert hashes to map
+	expectedHashesMap := make(map[string]*data.HashDataOutput)
+	for _, h := range expectedHashes {
+		expectedHashesMap[h.FullFileName] = h
+	}
+	return expectedHashesMap, nil
+}
 
-		//convert hashes to map
-		expectedHashesMap := make(map[string]string)
-		for _, h := range expectedHashes {
-			expectedHashesMap[h.FullFileName] = h.Hash
+// tracksNonRegular reports whether the snapshot contains non-regular files.
+// Snapshots created before non-regular files were tracked contain regular
+// files only, the non-regular ones are not verified for them.
+func tracksNonRegular(expected map[string]*data.HashDataOutput) bool {
+	for _, h := range expected {
+		if h.Type != "" {
+			return true
 		}
+	}
+	return false
+}
 
-		for v := range hashC {
-			select {
-			case <-ctx.Done():
-				return
-			default:
+// compareWithExpected consumes calculated hashes from @hashC and matches them
+// against the @expected ones (keyed by the file path without the @prefix).
+// Violations are collected into the @report. Unless @fullDiff is set, it stops
+// on the first violation. Returns false if the context has been canceled.
+func compareWithExpected(
+	ctx context.Context,
+	log *logrus.Logger,
+	hashC <-chan worker.FileHash,
+	prefix string,
+	expected map[string]*data.HashDataOutput,
+	fullDiff bool,
+	report *Report,
+) bool {
+	// unblock workers if the comparison stops before all hashes are read
+	defer func() {
+		go func() {
+			for range hashC {
 			}
+		}()
+	}()
 
-			strippedPaths := strings.TrimPrefix(v.Path, procDirs)
-			if h, ok := expectedHashesMap[strippedPaths]; ok {
-				if h != v.Hash {
-					log.WithField("file", strippedPaths).WithError(fmt.Errorf("hashes not equal (expected/actual): %s != %s", h, v.Hash)).Error("compareHashes()")
-					errC <- &IntegrityError{Type: ErrTypeFileMismatch, Path: strippedPaths, Hash: v.Hash}
-					return
-				}
-				log.WithField("file", strippedPaths).Debug("compareHashess(): OK")
-				delete(expectedHashesMap, strippedPaths)
-			} else {
-				log.WithField("path", strippedPaths).Error("compareHashes(): new file")
-				errC <- &IntegrityError{Type: ErrTypeNewFile, Path: strippedPaths, Hash: v.Hash}
-				return
-			}
+	trackNonRegular := tracksNonRegular(expected)
+	for v := range hashC {
+		select {
+		case <-ctx.Done():
+			return false
+		default:
 		}
-		for p, h := range expectedHashesMap {
-			log.WithField("file", p).Error("compareHashes(): file deleted")
-			errC <- &IntegrityError{Type: ErrTypeFileDeleted, Path: p, Hash: h}
-			return
+
+		if !verifyEntry(log, v, prefix, expected, trackNonRegular, report) && !fullDiff {
+			return true
 		}
-		doneC <- len(expectedHashes)
-	}()
-	return doneC
+	}
+
+	deleted := make([]string, 0, len(expected))
+	for p := range expected {
+		deleted = append(deleted, p)
+	}
+	sort.Strings(deleted)
+	for _, p := range deleted {
+		reportDeleted(log, p, expected[p], report)
+		if !fullDiff {
+			break
+		}
+	}
+	return true
+}
+
+// verifyEntry verifies the calculated hash @v against the @expected ones and
+// removes the verified record from @expected. Violations are added into the
+// @report. Returns false if any violation has been found.
+func verifyEntry(
+	log *logrus.Logger,
+	v worker.FileHash,
+	prefix string,
+	expected map[string]*data.HashDataOutput,
+	trackNonRegular bool,
+	report *Report,
+) bool {
+	if v.Type != "" && !trackNonRegular {
+		return true
+	}
+	strippedPaths := relativePath(prefix, v.Path)
+	report.Checked++
+	h, ok := expected[strippedPaths]
+	if !ok {
+		log.WithField("path", strippedPaths).Error("compareHashes(): new file")
+		report.add(&IntegrityError{Type: newFileErrType(v.Type), Path: strippedPaths, Actual: describeFile(v.Hash, v.Type, v.Target)})
+		return false
+	}
+
+	delete(expected, strippedPaths)
+	violations := verifyFile(log, strippedPaths, h, v)
+	if len(violations) == 0 {
+		log.WithField("file", strippedPaths).Debug("compareHashess(): OK")
+		return true
+	}
+	for _, e := range violations {
+		report.add(e)
+	}
+	return false
+}
+
+// reportDeleted adds the violation for the deleted @path into the @report.
+func reportDeleted(log *logrus.Logger, path string, h *data.HashDataOutput, report *Report) {
+	log.WithField("file", path).Error("compareHashes(): file deleted")
+	errType := ErrTypeFileDeleted
+	if h.Type == data.TypeDir {
+		errType = ErrTypeDirDeleted
+	}
+	report.add(&IntegrityError{Type: errType, Path: path, Expected: describeFile(h.Hash, h.Type, h.Target)})
+}
+
+// verifyFile matches the @actual file state against the @expected snapshot
+// record and returns the found violations.
+func verifyFile(log *logrus.Logger, path string, expected *data.HashDataOutput, actual worker.FileHash) []*IntegrityError {
+	var violations []*IntegrityError
+	switch {
+	case expected.Type != actual.Type:
+		exp, act := describeFile(expected.Hash, expected.Type, expected.Target), describeFile(actual.Hash, actual.Type, actual.Target)
+		log.WithField("file", path).WithError(fmt.Errorf("file types not equal (expected/actual): %s != %s", exp, act)).Error("compareHashes()")
+		violations = append(violations, &IntegrityError{Type: ErrTypeFileMismatch, Path: path, Expected: exp, Actual: act})
+	case expected.Type == data.TypeSymlink && expected.Target != actual.Target:
+		log.WithField("file", path).WithError(fmt.Errorf("symlink targets not equal (expected/actual): %s != %s", expected.Target, actual.Target)).Error("compareHashes()")
+		violations = append(violations, &IntegrityError{Type: ErrTypeSymlinkMismatch, Path: path, Expected: expected.Target, Actual: actual.Target})
+	case expected.Target != actual.Target:
+		exp, act := describeFile(expected.Hash, expected.Type, expected.Target), describeFile(actual.Hash, actual.Type, actual.Target)
+		log.WithField("file", path).WithError(fmt.Errorf("devices not equal (expected/actual): %s != %s", exp, act)).Error("compareHashes()")
+		violations = append(violations, &IntegrityError{Type: ErrTypeFileMismatch, Path: path, Expected: exp, Actual: act})
+	case actual.Oversize == worker.OversizeViolation:
+		act := strconv.FormatInt(fileSize(actual), 10)
+		log.WithField("file", path).WithError(fmt.Errorf("file size %s exceeds the limit", act)).Error("compareHashes()")
+		violations = append(violations, &IntegrityError{Type: ErrTypeOversizeFile, Path: path, Actual: act})
+	case actual.Oversize == worker.OversizeSkip || expected.Hash == data.NoHash:
+		// the content of the files exceeding the size limit is not verified
+		log.WithField("file", path).Debug("compareHashes(): content verification skipped")
+	case expected.Hash != actual.Hash:
+		log.WithField("file", path).WithError(fmt.Errorf("hashes not equal (expected/actual): %s != %s", expected.Hash, actual.Hash)).Error("compareHashes()")
+		violations = append(violations, &IntegrityError{Type: ErrTypeFileMismatch, Path: path, Expected: expected.Hash, Actual: actual.Hash})
+	}
+
+	// metadata is verified only if the snapshot contains it
+	if expected.Meta != nil && actual.Meta != nil {
+		if exp, act := expected.Meta.Diff(actual.Meta, expected.Type); len(exp) > 0 || len(act) > 0 {
+			log.WithField("file", path).WithError(fmt.Errorf("metadata not equal (expected/actual): %v != %v", exp, act)).Error("compareHashes()")
+			violations = append(violations, &IntegrityError{Type: ErrTypeMetadataMismatch, Path: path,
+				Expected: strings.Join(exp, " "), Actual: strings.Join(act, " ")})
+		}
+	}
+	return violations
+}
+
+// newFileErrType returns the violation type for a new file of the @typ type.
+func newFileErrType(typ string) int {
+	switch typ {
+	case data.TypeDir:
+		return ErrTypeDirAdded
+	case data.TypeCharDev, data.TypeBlockDev, data.TypeFifo:
+		return ErrTypeNewSpecialFile
+	}
+	return ErrTypeNewFile
+}
+
+// fileSize returns the size of the file if its metadata is known.
+func fileSize(f worker.FileHash) int64 {
+	if f.Meta == nil {
+		return 0
+	}
+	return f.Meta.Size
+}
+
+// describeFile returns the hash for regular files, otherwise the type of the
+// file with its target if any, e.g. "symlink:/usr/bin/python3".
+func describeFile(hash, typ, target string) string {
+	switch {
+	case typ == "":
+		return hash
+	case target == "":
+		return typ
+	}
+	return typ + ":" + target
 }
 
 func integrityCheckFailed(
+	ctx context.Context,
 	log *logrus.Logger,
-	err error,
+	report *Report,
 	deploymentData *k8s.DeploymentData,
-	kubeClient *k8s.KubeClient,
-	procName string,
+	kubeClient k8s.IKuberService,
 ) {
-	log.WithError(err).Error("check integrity failed")
-	var (
-		mPath          string
-		integrityError *IntegrityError
-		alertErr       error
+	log.WithContext(ctx).WithError(report).Error("check integrity failed")
+	report.Pod = deploymentData.NamePod
+	for _, v := range report.Violations {
+		metrics.Violations.WithLabelValues(report.ProcessName, v.Error()).Inc()
+		log.WithContext(ctx).WithFields(logrus.Fields{
+			"path":     v.Path,
+			"reason":   v.Error(),
+			"expected": v.Expected,
+			"actual":   v.Actual,
+		}).Error("integrity violation")
+	}
+
+	responder, err := NewResponder(report.ProcessName, kubeClient)
+	if err != nil {
+		log.WithError(err).Error("failed get responder, fallback to alert only")
+		responder = responders[ActionAlert](kubeClient)
+	}
+	var audit *auditResponder
+	if AuditMode(report.ProcessName) {
+		audit = newAuditResponder(responder)
+		responder = audit
+	}
+
+	// the violating files are gone with the pod after the response action
+	var evidence string
+	if viper.GetString("evidence-bucket") != "" {
+		evidence, err = captureEvidence(ctx, log, report, deploymentData.NamePod)
+		if err != nil {
+			log.WithError(err).Error("failed capture evidence")
+		}
+	}
+
+	paths := strings.Join(report.Paths(), ",")
+	alert := alerts.New(responder.Message(deploymentData.NamePod),
+		report.Reason(),
+		paths,
+		report.ProcessName,
 	)
+	alert.Audit = audit != nil
+	alert.Evidence = evidence
+	alertErr := alerts.Send(ctx, alert)
+	if alertErr != nil {
+		log.WithError(alertErr).Error("Failed send alert")
+	}
+	emitViolationEvent(ctx, log, report, audit != nil, kubeClient)
 
-	if errors.As(err, &integrityError) {
-		mPath = integrityError.Path
-		log.WithField("path", integrityError.Path)
-		alertErr = alerts.Send(alerts.New(fmt.Sprintf("Restart pod %v", deploymentData.NamePod),
-			err.Error(),
-			mPath,
-			procName,
+	respondCtx, span := tracing.Start(ctx, "Respond", attribute.String("action", responder.Action()),
+		attribute.Bool("audit", audit != nil))
+	err = responder.Respond(respondCtx, report)
+	tracing.End(span, err)
+	result := metrics.Result(err)
+	if audit != nil {
+		result = "audit"
+	}
+	metrics.ResponseActions.WithLabelValues(report.ProcessName, responder.Action(), result).Inc()
+	if err != nil {
+		log.WithContext(ctx).WithError(err).WithField("action", responder.Action()).Error("response action failed")
+		alertErr = alerts.Send(ctx, alerts.New(fmt.Sprintf("Response action %q failed for pod %v: %v", responder.Action(), deploymentData.NamePod, err),
+			IntegrityMessageResponseFailed,
+			paths,
+			report.ProcessName,
 		))
 		if alertErr != nil {
 			log.WithError(alertErr).Error("Failed send alert")
 		}
-		kubeClient.RestartPod()
+	}
+	if audit != nil {
+		log.WithFields(logrus.Fields{
+			"process":    report.ProcessName,
+			"action":     audit.Action(),
+			"actions":    audit.total.Actions,
+			"violations": audit.total.Violations,
+		}).Warn("audit mode, response action not performed")
 	}
 }
 
@@ -1,8 +1,17 @@
 package integritymonitor
 
 import (
+	"context"
 	"reflect"
 	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ScienceSoft-Inc/integrity-sum/internal/data"
+	"github.com/ScienceSoft-Inc/integrity-sum/internal/worker"
+	"github.com/ScienceSoft-Inc/integrity-sum/pkg/alerts/syslog"
 )
 
 func Test_parseOpts(t *testing.T) {
@@ -71,3 +80,48 @@ func Test_parseOpts(t *testing.T) {
 		})
 	}
 }
+
+func TestCompareHashesCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// nobody receives the report of the canceled scan
+	hashC := make(chan worker.FileHash, 1)
+	hashC <- worker.FileHash{Path: "/etc/app.conf", Hash: "1"}
+	close(hashC)
+	doneC := compareHashes(ctx, logrus.New(), hashC, "app", "/", map[string]*data.HashDataOutput{}, false)
+	select {
+	case report := <-doneC:
+		assert.Nil(t, report)
+	case <-time.After(time.Second):
+		t.Fatal("compareHashes is blocked")
+	}
+}
+
+func TestWithMetaFilter(t *testing.T) {
+	expected := map[string]*data.HashDataOutput{
+		"etc/app.conf": {Hash: "1", Meta: &data.FileMeta{Mode: 0o644}},
+		"etc/other":    {Hash: "2"},
+	}
+	want := withMetaFilter("/proc/1/root/", expected)
+	// the records are removed while they are verified
+	delete(expected, "etc/app.conf")
+	assert.True(t, want("/proc/1/root/etc/app.conf"))
+	assert.False(t, want("/proc/1/root/etc/other"))
+	assert.False(t, want("/proc/1/root/etc/new"))
+}
+
+func TestAlertReasonTypes(t *testing.T) {
+	// the reasons of the sent alerts have their syslog event types
+	for _, reason := range []string{
+		IntegrityMessageNewFileFound, IntegrityMessageFileDeleted, IntegrityMessageFileMismatch,
+		IntegrityMessageMultipleErrs, IntegrityMessageMetadataMismatch, IntegrityMessageSymlinkMismatch,
+		IntegrityMessageNewSpecialFile, IntegrityMessageDirAdded, IntegrityMessageDirDeleted,
+		IntegrityMessageOversizeFile, IntegrityMessageDeletedExecutable, IntegrityMessageUnknownExecutable,
+		IntegrityMessageUnexpectedProcess, IntegrityMessageUnexpectedListener, IntegrityMessageBaselineLearned,
+		IntegrityMessageBaselineUpdated, IntegrityMessageFilesRestored, IntegrityMessageRestoreFailed,
+		IntegrityMessageResponseFailed,
+	} {
+		assert.NotZero(t, syslog.ErrToType[reason], reason)
+	}
+}
@@ -0,0 +1,150 @@
+package integritymonitor
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+
+	"github.com/ScienceSoft-Inc/integrity-sum/internal/utils/process"
+	"github.com/ScienceSoft-Inc/integrity-sum/internal/walker"
+	"github.com/ScienceSoft-Inc/integrity-sum/internal/worker"
+	"github.com/ScienceSoft-Inc/integrity-sum/pkg/alerts"
+	"github.com/ScienceSoft-Inc/integrity-sum/pkg/k8s"
+	"github.com/ScienceSoft-Inc/integrity-sum/pkg/minio"
+)
+
+// Baseline learning modes
+const (
+	LearnOff      = "off"
+	LearnMinIO    = "minio"
+	LearnSnapshot = "snapshot"
+)
+
+var LearnModes = []string{LearnOff, LearnMinIO, LearnSnapshot}
+
+var (
+	learnedMu sync.Mutex
+	learned   = make(map[string]bool)
+)
+
+// ValidateLearnMode checks the configured baseline learning mode.
+func ValidateLearnMode() error {
+	mode := viper.GetString("learn-baseline")
+	for _, m := range LearnModes {
+		if m == mode {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown baseline learning mode %q, available: %s", mode, strings.Join(LearnModes, ", "))
+}
+
+// learnBaseline hashes the @monitoringDirectories of the first instance of the
+// @processName process and stores the result as its snapshot if no snapshot
+// exists yet (trust on first use). Returns true if the snapshot is not
+// available for the verification in this cycle.
+func learnBaseline(ctx context.Context, log *logrus.Logger, processName string, roots, monitoringDirectories []string,
+	deploymentData *k8s.DeploymentData, kubeClient k8s.IKuberService) (bool, error) {
+	mode := viper.GetString("learn-baseline")
+	if mode == LearnOff || len(roots) == 0 {
+		return false, nil
+	}
+
+	algName := viper.GetString("algorithm")
+	csFile, err := process.CheckSumFile(processName, algName)
+	if err != nil {
+		return false, fmt.Errorf("failed getting check sum file name: %w", err)
+	}
+	exists, err := minio.Instance().Exists(ctx, viper.GetString("minio-bucket"), csFile)
+	if err != nil {
+		return false, err
+	}
+	if exists {
+		return false, nil
+	}
+
+	learnedMu.Lock()
+	defer learnedMu.Unlock()
+	if learned[processName] {
+		// the snapshot-controller has not uploaded the learned snapshot yet
+		log.WithField("process", processName).Info("waiting for the learned baseline to be uploaded")
+		return true, nil
+	}
+
+	root := roots[0]
+	hashes, err := hashRoot(ctx, log, root, monitoringDirectories, algName)
+	if err != nil {
+		return false, err
+	}
+	var buf bytes.Buffer
+	if err := writeAsPlainText(&buf, hashes, false); err != nil {
+		return false, err
+	}
+
+	location := csFile
+	switch mode {
+	case LearnMinIO:
+		err = minio.Instance().Save(ctx, viper.GetString("minio-bucket"), csFile, buf.Bytes())
+	case LearnSnapshot:
+		image := viper.GetStringMapString("process-image")[processName]
+		location, err = kubeClient.CreateSnapshot(ctx, image, algName, buf.Bytes())
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed store learned baseline: %w", err)
+	}
+	learned[processName] = true
+	if viper.GetString("golden-bucket") != "" {
+		if err := uploadGoldenCopies(ctx, log, root, hashes, algName); err != nil {
+			log.WithError(err).Error("failed store golden copies")
+		}
+	}
+
+	log.WithFields(logrus.Fields{
+		"process":    processName,
+		"pod":        deploymentData.NamePod,
+		"root":       root,
+		"countFiles": len(hashes),
+		"mode":       mode,
+		"location":   location,
+	}).Warn("baseline learned on first use")
+	alertErr := alerts.Send(ctx, alerts.New(
+		fmt.Sprintf("Baseline of %d files learned from pod %v and stored to %v", len(hashes), deploymentData.NamePod, mode),
+		IntegrityMessageBaselineLearned,
+		location,
+		processName,
+	))
+	if alertErr != nil {
+		log.WithError(alertErr).Error("Failed send alert")
+	}
+	return true, nil
+}
+
+// hashRoot calculates the hashes of the @monitoringDirectories in the @root
+// file system view. The paths are returned relative to the @root and sorted.
+func hashRoot(ctx context.Context, log *logrus.Logger, root string, monitoringDirectories []string, algName string) ([]worker.FileHash, error) {
+	paths := make([]string, len(monitoringDirectories))
+	for i, p := range monitoringDirectories {
+		paths[i] = root + p
+	}
+
+	hashC := worker.WorkersPool(
+		viper.GetInt("count-workers"),
+		walker.ChanWalkDir(ctx, paths, log),
+		worker.NewWorker(ctx, algName, log, workerOptions()...),
+	)
+	hashes := make([]worker.FileHash, 0, DefaultHashSize)
+	for v := range hashC {
+		v.Path = relativePath(root, v.Path)
+		hashes = append(hashes, v)
+	}
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	sort.Slice(hashes, func(i, j int) bool { return hashes[i].Path < hashes[j].Path })
+	return hashes, nil
+}
@@ -0,0 +1,62 @@
+package integritymonitor
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ScienceSoft-Inc/integrity-sum/internal/data"
+)
+
+func TestHashRootVerifiesClean(t *testing.T) {
+	root := t.TempDir() + "/"
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "app", "conf"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "app", "app.py"), []byte("print(1)"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "app", "conf", "app.ini"), []byte("[app]"), 0o644))
+
+	viper.Set("count-workers", 2)
+	defer viper.Set("count-workers", 0)
+
+	log := logrus.New()
+	hashes, err := hashRoot(context.Background(), log, root, []string{"app"}, "sha256")
+	require.NoError(t, err)
+	paths := make([]string, len(hashes))
+	for i, h := range hashes {
+		paths[i] = h.Path
+	}
+	assert.Equal(t, []string{"app", "app/app.py", "app/conf", "app/conf/app.ini"}, paths)
+
+	// the learned baseline is verified without violations
+	var buf bytes.Buffer
+	require.NoError(t, writeAsPlainText(&buf, hashes, false))
+	records, err := data.NewFileStorage(&buf).Get()
+	require.NoError(t, err)
+	expected := make(map[string]*data.HashDataOutput)
+	for _, r := range records {
+		expected[r.FullFileName] = r
+	}
+	report := NewReport("app")
+	for _, h := range hashes {
+		h.Path = root + h.Path
+		assert.True(t, verifyEntry(log, h, root, expected, tracksNonRegular(expected), report), h.Path)
+	}
+	assert.Empty(t, expected)
+	assert.False(t, report.HasViolations())
+}
+
+func TestValidateLearnMode(t *testing.T) {
+	defer viper.Set("learn-baseline", "")
+	for _, mode := range LearnModes {
+		viper.Set("learn-baseline", mode)
+		assert.NoError(t, ValidateLearnMode(), mode)
+	}
+	viper.Set("learn-baseline", "database")
+	assert.Error(t, ValidateLearnMode())
+}
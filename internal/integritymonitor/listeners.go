@@ -0,0 +1,268 @@
+package integritymonitor
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+
+	"github.com/ScienceSoft-Inc/integrity-sum/internal/utils/process"
+	"github.com/ScienceSoft-Inc/integrity-sum/pkg/minio"
+)
+
+// listenBaselineSuffix is the suffix of the MinIO object the learned listeners
+// are stored in along with the baseline, <checksum object>.listeners
+const listenBaselineSuffix = ".listeners"
+
+// listenRule permits the listeners with the protocol and the port on the IP,
+// nil IP means any address.
+type listenRule struct {
+	proto string
+	ip    net.IP
+	port  int
+}
+
+// String returns the rule in the form parsed by parseListenRules.
+func (r listenRule) String() string {
+	if r.ip == nil {
+		return r.proto + ":" + strconv.Itoa(r.port)
+	}
+	return r.proto + ":" + net.JoinHostPort(r.ip.String(), strconv.Itoa(r.port))
+}
+
+func (r listenRule) allows(l process.Listener) bool {
+	return r.proto == l.Proto && r.port == l.Port && (r.ip == nil || r.ip.Equal(l.IP))
+}
+
+// parseListenRules parses the rules in the "proto:[ip:]port[;...]" form, e.g.
+// "tcp:80;tcp6:80;udp:127.0.0.1:53".
+func parseListenRules(spec string) ([]listenRule, error) {
+	var rules []listenRule
+	for _, r := range strings.Split(spec, ";") {
+		r = strings.TrimSpace(r)
+		proto, addr, _ := strings.Cut(r, ":")
+		switch proto {
+		case "tcp", "tcp6", "udp", "udp6":
+		default:
+			return nil, fmt.Errorf("incorrect listener %q, protocol tcp, tcp6, udp or udp6 expected", r)
+		}
+		rule := listenRule{proto: proto}
+		portStr := addr
+		if i := strings.LastIndex(addr, ":"); i >= 0 {
+			portStr = addr[i+1:]
+			rule.ip = net.ParseIP(strings.Trim(addr[:i], "[]"))
+			if rule.ip == nil {
+				return nil, fmt.Errorf("incorrect listener %q, invalid ip", r)
+			}
+		}
+		port, err := strconv.ParseUint(portStr, 10, 16)
+		if err != nil {
+			return nil, fmt.Errorf("incorrect listener %q, invalid port: %w", r, err)
+		}
+		rule.port = int(port)
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// listenBaseline is the set of the listeners expected for a process, either
+// declared or learned during the "listen-learn-period" after the start. The
+// learned baseline is stored in MinIO so that a restarted monitor does not
+// learn the listeners of a compromised pod again.
+type listenBaseline struct {
+	rules      []listenRule
+	learnUntil time.Time
+	// stored is false until the learned baseline is saved
+	stored bool
+}
+
+func (b *listenBaseline) allows(l process.Listener) bool {
+	for _, r := range b.rules {
+		if r.allows(l) {
+			return true
+		}
+	}
+	return false
+}
+
+// learn adds the @l listener into the baseline if it is still learned.
+func (b *listenBaseline) learn(l process.Listener, now time.Time) bool {
+	if !now.Before(b.learnUntil) {
+		return false
+	}
+	b.rules = append(b.rules, listenRule{proto: l.Proto, port: l.Port})
+	return true
+}
+
+var (
+	listenBaselinesMu sync.Mutex
+	listenBaselines   = make(map[string]*listenBaseline)
+)
+
+// processListenBaseline returns the listeners baseline of the @procName
+// process: declared with the "listen-allowlist" option, previously learned and
+// stored in MinIO, or to be learned.
+func processListenBaseline(ctx context.Context, procName string) (*listenBaseline, error) {
+	listenBaselinesMu.Lock()
+	defer listenBaselinesMu.Unlock()
+	if b, ok := listenBaselines[procName]; ok {
+		return b, nil
+	}
+
+	b := &listenBaseline{stored: true}
+	if spec, ok := viper.GetStringMapString("listen-allowlist")[procName]; ok {
+		rules, err := parseListenRules(spec)
+		if err != nil {
+			return nil, fmt.Errorf("process %s: %w", procName, err)
+		}
+		b.rules = rules
+	} else {
+		rules, found, err := loadListenBaseline(ctx, procName)
+		if err != nil {
+			return nil, fmt.Errorf("process %s: failed load learned listeners: %w", procName, err)
+		}
+		if found {
+			b.rules = rules
+		} else {
+			b.learnUntil = time.Now().Add(viper.GetDuration("listen-learn-period"))
+			b.stored = false
+		}
+	}
+	listenBaselines[procName] = b
+	return b, nil
+}
+
+// listenBaselineObject returns the name of the MinIO object of the learned
+// listeners of the @procName process.
+func listenBaselineObject(procName string) (string, error) {
+	csFile, err := process.CheckSumFile(procName, viper.GetString("algorithm"))
+	if err != nil {
+		return "", err
+	}
+	return csFile + listenBaselineSuffix, nil
+}
+
+// loadListenBaseline loads the learned listeners of the @procName process,
+// found is false if they are not stored yet.
+func loadListenBaseline(ctx context.Context, procName string) (rules []listenRule, found bool, err error) {
+	object, err := listenBaselineObject(procName)
+	if err != nil {
+		return nil, false, err
+	}
+	bucket := viper.GetString("minio-bucket")
+	exists, err := minio.Instance().Exists(ctx, bucket, object)
+	if err != nil || !exists {
+		return nil, false, err
+	}
+	data, err := minio.Instance().Load(ctx, bucket, object)
+	if err != nil {
+		return nil, false, err
+	}
+	if spec := strings.TrimSpace(string(data)); spec != "" {
+		if rules, err = parseListenRules(spec); err != nil {
+			return nil, false, fmt.Errorf("%s: %w", object, err)
+		}
+	}
+	return rules, true, nil
+}
+
+// storeListenBaseline saves the listeners learned for the @procName process.
+func storeListenBaseline(ctx context.Context, procName string, b *listenBaseline) error {
+	object, err := listenBaselineObject(procName)
+	if err != nil {
+		return err
+	}
+	return minio.Instance().Save(ctx, viper.GetString("minio-bucket"), object, []byte(formatListenRules(b.rules)))
+}
+
+// formatListenRules returns the @rules in the form parsed by parseListenRules.
+func formatListenRules(rules []listenRule) string {
+	specs := make([]string, len(rules))
+	for i, r := range rules {
+		specs[i] = r.String()
+	}
+	return strings.Join(specs, ";")
+}
+
+// ValidateListenAllowlists checks the listeners declared for the @procNames
+// processes.
+func ValidateListenAllowlists(procNames []string) error {
+	allowlists := viper.GetStringMapString("listen-allowlist")
+	for _, proc := range procNames {
+		if spec, ok := allowlists[proc]; ok {
+			if _, err := parseListenRules(spec); err != nil {
+				return fmt.Errorf("process %s: %w", proc, err)
+			}
+		}
+	}
+	return nil
+}
+
+// verifyListeners reports the listening sockets seen by the @processName
+// instances which are not in the baseline. The sockets are read per network
+// namespace, i.e. for the whole pod, the sockets of the monitor itself are
+// skipped. The learned baseline is stored once the learning period is over.
+func verifyListeners(ctx context.Context, log *logrus.Logger, processName string) (*Report, error) {
+	pids, err := process.GetPIDs(processName)
+	if err != nil {
+		return nil, err
+	}
+	baseline, err := processListenBaseline(ctx, processName)
+	if err != nil {
+		return nil, err
+	}
+	own, err := process.SocketInodes(os.Getpid())
+	if err != nil {
+		log.WithError(err).Warn("failed read own sockets")
+	}
+
+	now := time.Now()
+	report := NewReport(processName)
+	seen := make(map[string]struct{})
+	for _, pid := range pids {
+		listeners, err := process.Listeners(pid)
+		if err != nil {
+			// the process might exit meanwhile
+			log.WithError(err).WithField("pid", pid).Warn("failed read process listeners")
+			continue
+		}
+		for _, l := range listeners {
+			if _, ok := own[l.Inode]; ok {
+				continue
+			}
+			if _, ok := seen[l.Inode+l.String()]; ok {
+				continue
+			}
+			seen[l.Inode+l.String()] = struct{}{}
+
+			report.Checked++
+			if baseline.allows(l) {
+				continue
+			}
+			if baseline.learn(l, now) {
+				log.WithField("listener", l.String()).Info("listener added into the baseline")
+				continue
+			}
+			log.WithField("listener", l.String()).Error("verifyListeners(): unexpected listener")
+			report.add(&IntegrityError{Type: ErrTypeUnexpectedListener, Path: l.String()})
+		}
+	}
+
+	if !baseline.stored && !now.Before(baseline.learnUntil) {
+		if err := storeListenBaseline(ctx, processName, baseline); err != nil {
+			// retried on the next check
+			log.WithError(err).Error("failed store learned listeners")
+		} else {
+			baseline.stored = true
+			log.WithField("listeners", formatListenRules(baseline.rules)).Info("learned listeners stored")
+		}
+	}
+	return report, nil
+}
@@ -0,0 +1,62 @@
+package integritymonitor
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ScienceSoft-Inc/integrity-sum/internal/utils/process"
+)
+
+func TestListenBaseline(t *testing.T) {
+	rules, err := parseListenRules("tcp:80;tcp6:[::1]:8080;udp:127.0.0.1:53")
+	require.NoError(t, err)
+	b := &listenBaseline{rules: rules}
+
+	tests := []struct {
+		l    process.Listener
+		want bool
+	}{
+		{l: process.Listener{Proto: "tcp", IP: net.IPv4zero, Port: 80}, want: true},
+		{l: process.Listener{Proto: "tcp", IP: net.IPv4(10, 0, 0, 1), Port: 80}, want: true},
+		{l: process.Listener{Proto: "tcp6", IP: net.IPv6loopback, Port: 8080}, want: true},
+		{l: process.Listener{Proto: "tcp6", IP: net.IPv6zero, Port: 8080}},
+		{l: process.Listener{Proto: "udp", IP: net.IPv4zero, Port: 53}},
+		{l: process.Listener{Proto: "tcp6", IP: net.IPv6zero, Port: 80}},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, b.allows(tt.l), tt.l.String())
+	}
+
+	for _, spec := range []string{"", "sctp:80", "tcp:http", "tcp:localhost:80", "tcp:70000"} {
+		_, err = parseListenRules(spec)
+		assert.Error(t, err, spec)
+	}
+}
+
+func TestListenBaselineLearn(t *testing.T) {
+	now := time.Now()
+	b := &listenBaseline{learnUntil: now.Add(time.Minute)}
+	l := process.Listener{Proto: "tcp", IP: net.IPv4zero, Port: 80}
+	assert.False(t, b.allows(l))
+	assert.True(t, b.learn(l, now))
+	assert.True(t, b.allows(l))
+
+	backdoor := process.Listener{Proto: "tcp", IP: net.IPv4zero, Port: 4444}
+	assert.False(t, b.learn(backdoor, now.Add(time.Minute)))
+	assert.False(t, b.allows(backdoor))
+}
+
+func TestFormatListenRules(t *testing.T) {
+	spec := "tcp:80;tcp6:[::1]:8080;udp:127.0.0.1:53"
+	rules, err := parseListenRules(spec)
+	require.NoError(t, err)
+	assert.Equal(t, spec, formatListenRules(rules))
+
+	b := &listenBaseline{learnUntil: time.Now().Add(time.Minute)}
+	b.learn(process.Listener{Proto: "tcp6", IP: net.IPv6zero, Port: 443}, time.Now())
+	assert.Equal(t, "tcp6:443", formatListenRules(b.rules))
+}
@@ -0,0 +1,269 @@
+package integritymonitor
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+
+	"github.com/ScienceSoft-Inc/integrity-sum/internal/data"
+	"github.com/ScienceSoft-Inc/integrity-sum/internal/utils/process"
+	"github.com/ScienceSoft-Inc/integrity-sum/internal/worker"
+	"github.com/ScienceSoft-Inc/integrity-sum/pkg/alerts"
+	"github.com/ScienceSoft-Inc/integrity-sum/pkg/k8s"
+	"github.com/ScienceSoft-Inc/integrity-sum/pkg/minio"
+)
+
+const (
+	baselineVersionLayout = "20060102T150405Z"
+	provenanceSuffix      = ".provenance.json"
+)
+
+// Provenance describes who requested the baseline and how it differs from the
+// previous one. It is stored along with the baseline as
+// <checksum object>.provenance.json. RequestedBy is the unverified value of
+// the rebaseline annotation, the user who set it is in the audit log of the
+// cluster.
+type Provenance struct {
+	Version     string           `json:"version"`
+	RequestedBy string           `json:"requestedBy"`
+	Pod         string           `json:"pod"`
+	Time        time.Time        `json:"time"`
+	Files       int              `json:"files"`
+	Previous    string           `json:"previous,omitempty"`
+	Changes     []BaselineChange `json:"changes"`
+}
+
+// BaselineChange is a difference between the previous and the new baseline.
+type BaselineChange struct {
+	Path     string `json:"path"`
+	Change   string `json:"change"`
+	Previous string `json:"previous,omitempty"`
+	Current  string `json:"current,omitempty"`
+}
+
+// Rebaseline approves the current state of the monitored processes as their
+// new baseline if it is requested by the rebaseline annotation of the pod. The
+// annotation is removed afterwards and the result is stored in the rebaseline
+// status annotation.
+func Rebaseline(ctx context.Context, log *logrus.Logger, optsMap map[string][]string,
+	deploymentData *k8s.DeploymentData, kubeClient k8s.IKuberService) error {
+	annotations, err := kubeClient.GetPodAnnotations(ctx)
+	if err != nil {
+		return fmt.Errorf("failed get pod annotations: %w", err)
+	}
+	requestedBy := strings.TrimSpace(annotations[k8s.AnnotationRebaseline])
+	if requestedBy == "" {
+		return nil
+	}
+
+	procNames := rebaselineProcesses(annotations[k8s.AnnotationRebaselineProcesses], optsMap)
+	status := make([]string, 0, len(procNames))
+	for _, proc := range procNames {
+		paths, ok := optsMap[proc]
+		if !ok {
+			status = append(status, proc+": unknown process")
+			continue
+		}
+		version, err := rebaselineProcess(ctx, log, proc, paths, requestedBy, deploymentData)
+		if err != nil {
+			log.WithError(err).WithField("process", proc).Error("rebaseline failed")
+			status = append(status, fmt.Sprintf("%s: failed: %v", proc, err))
+			continue
+		}
+		status = append(status, proc+": "+version)
+	}
+
+	result := strings.Join(status, ", ")
+	return kubeClient.AnnotatePod(ctx, map[string]*string{
+		k8s.AnnotationRebaseline:          nil,
+		k8s.AnnotationRebaselineProcesses: nil,
+		k8s.AnnotationRebaselineStatus:    &result,
+	})
+}
+
+// rebaselineProcesses returns the sorted names of the processes listed in the
+// comma separated @list, all monitored processes if it is empty.
+func rebaselineProcesses(list string, optsMap map[string][]string) []string {
+	var names []string
+	for _, p := range strings.Split(list, ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			names = append(names, p)
+		}
+	}
+	if len(names) == 0 {
+		for proc := range optsMap {
+			names = append(names, proc)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// rebaselineProcess hashes the @monitoringDirectories of the first instance of
+// the @processName process and uploads the result as its new baseline with the
+// provenance. The previous baseline and its provenance are kept as
+// <checksum object>.<version>. Returns the version of the new baseline.
+func rebaselineProcess(ctx context.Context, log *logrus.Logger, processName string, monitoringDirectories []string,
+	requestedBy string, deploymentData *k8s.DeploymentData) (string, error) {
+	unlock := lockProcess(processName)
+	defer unlock()
+
+	roots, err := process.Roots(processName)
+	if err != nil {
+		return "", err
+	}
+	if len(roots) == 0 {
+		return "", fmt.Errorf("process %s is not running", processName)
+	}
+	algName := viper.GetString("algorithm")
+	csFile, err := process.CheckSumFile(processName, algName)
+	if err != nil {
+		return "", fmt.Errorf("failed getting check sum file name: %w", err)
+	}
+
+	hashes, err := hashRoot(ctx, log, roots[0], monitoringDirectories, algName)
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now().UTC()
+	prov := &Provenance{
+		Version:     now.Format(baselineVersionLayout),
+		RequestedBy: requestedBy,
+		Pod:         deploymentData.NamePod,
+		Time:        now,
+		Files:       len(hashes),
+	}
+	// the metadata is verified only if the baseline has it, so it is kept
+	withMeta := false
+
+	ms := minio.Instance()
+	bucket := viper.GetString("minio-bucket")
+	exists, err := ms.Exists(ctx, bucket, csFile)
+	if err != nil {
+		return "", err
+	}
+	if exists {
+		previous, err := ms.Load(ctx, bucket, csFile)
+		if err != nil {
+			return "", fmt.Errorf("cannot read hash data: %w", err)
+		}
+		expected, err := data.NewFileStorage(bytes.NewReader(previous)).Get()
+		if err != nil {
+			return "", fmt.Errorf("failed get hash data: %w", err)
+		}
+		prov.Changes = diffBaseline(expected, hashes)
+		withMeta = hasMeta(expected)
+
+		// keep the previous baseline and its provenance versioned
+		prov.Previous = csFile + "." + prov.Version
+		if err := ms.Save(ctx, bucket, prov.Previous, previous); err != nil {
+			return "", err
+		}
+		if ok, err := ms.Exists(ctx, bucket, csFile+provenanceSuffix); err == nil && ok {
+			prevProv, err := ms.Load(ctx, bucket, csFile+provenanceSuffix)
+			if err != nil {
+				return "", err
+			}
+			if err := ms.Save(ctx, bucket, prov.Previous+provenanceSuffix, prevProv); err != nil {
+				return "", err
+			}
+		}
+	}
+
+	var baseline bytes.Buffer
+	if err := writeAsPlainText(&baseline, hashes, withMeta); err != nil {
+		return "", err
+	}
+	provData, err := json.MarshalIndent(prov, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	if err := ms.Save(ctx, bucket, csFile, baseline.Bytes()); err != nil {
+		return "", err
+	}
+	if err := ms.Save(ctx, bucket, csFile+provenanceSuffix, provData); err != nil {
+		return "", err
+	}
+	if viper.GetString("golden-bucket") != "" {
+		if err := uploadGoldenCopies(ctx, log, roots[0], hashes, algName); err != nil {
+			log.WithError(err).Error("failed store golden copies")
+		}
+	}
+
+	for _, c := range prov.Changes {
+		log.WithFields(logrus.Fields{
+			"path":     c.Path,
+			"change":   c.Change,
+			"previous": c.Previous,
+			"current":  c.Current,
+		}).Info("baseline change")
+	}
+	log.WithFields(logrus.Fields{
+		"process":     processName,
+		"requestedBy": requestedBy,
+		"version":     prov.Version,
+		"previous":    prov.Previous,
+		"changes":     len(prov.Changes),
+	}).Warn("baseline updated")
+	alertErr := alerts.Send(ctx, alerts.New(
+		fmt.Sprintf("Baseline of pod %v updated to version %v requested by %v, %d changes", deploymentData.NamePod,
+			prov.Version, requestedBy, len(prov.Changes)),
+		IntegrityMessageBaselineUpdated,
+		csFile,
+		processName,
+	))
+	if alertErr != nil {
+		log.WithError(alertErr).Error("Failed send alert")
+	}
+	return prov.Version, nil
+}
+
+// hasMeta returns true if any record of the @baseline has the metadata.
+func hasMeta(baseline []*data.HashDataOutput) bool {
+	for _, h := range baseline {
+		if h.Meta != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// diffBaseline returns the differences of the @current hashes from the
+// @previous baseline.
+func diffBaseline(previous []*data.HashDataOutput, current []worker.FileHash) []BaselineChange {
+	expected := make(map[string]*data.HashDataOutput, len(previous))
+	for _, h := range previous {
+		expected[h.FullFileName] = h
+	}
+
+	quiet := logrus.New()
+	quiet.SetOutput(io.Discard)
+	report := NewReport("")
+	trackNonRegular := tracksNonRegular(expected)
+	for _, h := range current {
+		verifyEntry(quiet, h, "", expected, trackNonRegular, report)
+	}
+	deleted := make([]string, 0, len(expected))
+	for p := range expected {
+		deleted = append(deleted, p)
+	}
+	sort.Strings(deleted)
+	for _, p := range deleted {
+		reportDeleted(quiet, p, expected[p], report)
+	}
+
+	changes := make([]BaselineChange, len(report.Violations))
+	for i, v := range report.Violations {
+		changes[i] = BaselineChange{Path: v.Path, Change: v.Error(), Previous: v.Expected, Current: v.Actual}
+	}
+	return changes
+}
@@ -0,0 +1,73 @@
+package integritymonitor
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ScienceSoft-Inc/integrity-sum/internal/data"
+	"github.com/ScienceSoft-Inc/integrity-sum/internal/worker"
+	"github.com/ScienceSoft-Inc/integrity-sum/pkg/k8s"
+	mockk8s "github.com/ScienceSoft-Inc/integrity-sum/pkg/k8s/mocks"
+)
+
+func TestDiffBaseline(t *testing.T) {
+	previous := []*data.HashDataOutput{
+		{FullFileName: "etc/nginx/nginx.conf", Hash: "1"},
+		{FullFileName: "etc/nginx/mime.types", Hash: "2"},
+		{FullFileName: "etc/nginx/old.conf", Hash: "3"},
+	}
+	current := []worker.FileHash{
+		{Path: "etc/nginx/nginx.conf", Hash: "10"},
+		{Path: "etc/nginx/mime.types", Hash: "2"},
+		{Path: "etc/nginx/new.conf", Hash: "4"},
+	}
+
+	assert.Equal(t, []BaselineChange{
+		{Path: "etc/nginx/nginx.conf", Change: IntegrityMessageFileMismatch, Previous: "1", Current: "10"},
+		{Path: "etc/nginx/new.conf", Change: IntegrityMessageNewFileFound, Current: "4"},
+		{Path: "etc/nginx/old.conf", Change: IntegrityMessageFileDeleted, Previous: "3"},
+	}, diffBaseline(previous, current))
+	assert.Empty(t, diffBaseline(previous[1:2], current[1:2]))
+}
+
+func TestHasMeta(t *testing.T) {
+	assert.False(t, hasMeta([]*data.HashDataOutput{{FullFileName: "etc/nginx/nginx.conf"}}))
+	assert.True(t, hasMeta([]*data.HashDataOutput{
+		{FullFileName: "etc/nginx/nginx.conf"},
+		{FullFileName: "etc/nginx/mime.types", Meta: &data.FileMeta{}},
+	}))
+}
+
+func TestRebaselineProcesses(t *testing.T) {
+	optsMap := map[string][]string{"nginx": {"/etc"}, "app": {"/app"}}
+	assert.Equal(t, []string{"app", "nginx"}, rebaselineProcesses("", optsMap))
+	assert.Equal(t, []string{"ghost", "nginx"}, rebaselineProcesses(" nginx, ghost,", optsMap))
+}
+
+func TestRebaselineRequest(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	kc := mockk8s.NewMockIKuberService(ctrl)
+	optsMap := map[string][]string{"nginx": {"/etc"}}
+
+	// nothing is done without the request
+	kc.EXPECT().GetPodAnnotations(gomock.Any()).Return(map[string]string{}, nil)
+	require.NoError(t, Rebaseline(context.Background(), logrus.New(), optsMap, &k8s.DeploymentData{}, kc))
+
+	status := "ghost: unknown process"
+	kc.EXPECT().GetPodAnnotations(gomock.Any()).Return(map[string]string{
+		k8s.AnnotationRebaseline:          "jane.doe",
+		k8s.AnnotationRebaselineProcesses: "ghost",
+	}, nil)
+	kc.EXPECT().AnnotatePod(gomock.Any(), map[string]*string{
+		k8s.AnnotationRebaseline:          nil,
+		k8s.AnnotationRebaselineProcesses: nil,
+		k8s.AnnotationRebaselineStatus:    &status,
+	}).Return(nil)
+	require.NoError(t, Rebaseline(context.Background(), logrus.New(), optsMap, &k8s.DeploymentData{}, kc))
+}
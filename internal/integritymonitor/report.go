@@ -0,0 +1,102 @@
+package integritymonitor
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Report aggregates the integrity violations found during a single scan of
+// the process file system.
+type Report struct {
+	ProcessName string
+	// Pod is the name of the monitored pod, set when the violations are
+	// responded to
+	Pod string
+	// Root is the file system view "<proc-dir>/<pid>/root/" the violating
+	// files were found in
+	Root       string
+	Checked    int
+	Violations []*IntegrityError
+}
+
+// NewReport returns an empty report for the @procName process.
+func NewReport(procName string) *Report {
+	return &Report{ProcessName: procName}
+}
+
+func (r *Report) add(e *IntegrityError) {
+	r.Violations = append(r.Violations, e)
+}
+
+// RootOf returns the file system view the @v violating file was found in.
+func (r *Report) RootOf(v *IntegrityError) string {
+	if v.Root != "" {
+		return v.Root
+	}
+	return r.Root
+}
+
+// HasViolations reports whether at least one violation was found.
+func (r *Report) HasViolations() bool {
+	return len(r.Violations) > 0
+}
+
+// ByType returns violations of the @errType type.
+func (r *Report) ByType(errType int) []*IntegrityError {
+	var res []*IntegrityError
+	for _, v := range r.Violations {
+		if v.Type == errType {
+			res = append(res, v)
+		}
+	}
+	return res
+}
+
+// Paths returns paths of all violating files.
+func (r *Report) Paths() []string {
+	paths := make([]string, len(r.Violations))
+	for i, v := range r.Violations {
+		paths[i] = v.Path
+	}
+	return paths
+}
+
+// Reason returns the message of the violations if all of them have the same
+// type, otherwise the generic multiple violations message.
+func (r *Report) Reason() string {
+	if !r.HasViolations() {
+		return ""
+	}
+	reason := r.Violations[0].Error()
+	for _, v := range r.Violations[1:] {
+		if v.Error() != reason {
+			return IntegrityMessageMultipleErrs
+		}
+	}
+	return reason
+}
+
+// Error implements the error interface, so a report with violations may be
+// returned as a scan result.
+func (r *Report) Error() string {
+	counts := make(map[int]int)
+	for _, v := range r.Violations {
+		counts[v.Type]++
+	}
+	types := make([]int, 0, len(counts))
+	for k := range counts {
+		types = append(types, k)
+	}
+	sort.Ints(types)
+
+	summary := make([]string, len(types))
+	for i, t := range types {
+		summary[i] = fmt.Sprintf("%d %s", counts[t], (&IntegrityError{Type: t}).Error())
+	}
+	return fmt.Sprintf("%d integrity violation(s) (%s): %s",
+		len(r.Violations),
+		strings.Join(summary, ", "),
+		strings.Join(r.Paths(), ","),
+	)
+}
@@ -0,0 +1,171 @@
+package integritymonitor
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ScienceSoft-Inc/integrity-sum/internal/data"
+	"github.com/ScienceSoft-Inc/integrity-sum/internal/worker"
+)
+
+func expectedMap(hashes map[string]string) map[string]*data.HashDataOutput {
+	res := make(map[string]*data.HashDataOutput, len(hashes))
+	for k, v := range hashes {
+		res[k] = &data.HashDataOutput{FullFileName: k, Hash: v}
+	}
+	return res
+}
+
+func TestCompareWithExpected(t *testing.T) {
+	const prefix = "/proc/1/root/"
+	actual := []worker.FileHash{
+		{Path: prefix + "bin/ok", Hash: "1"},
+		{Path: prefix + "bin/changed", Hash: "x"},
+		{Path: prefix + "bin/new", Hash: "3"},
+	}
+	expected := func() map[string]*data.HashDataOutput {
+		return expectedMap(map[string]string{
+			"bin/ok":      "1",
+			"bin/changed": "2",
+			"bin/gone":    "4",
+			"bin/gone2":   "5",
+		})
+	}
+	hashC := func() <-chan worker.FileHash {
+		c := make(chan worker.FileHash, len(actual))
+		for _, v := range actual {
+			c <- v
+		}
+		close(c)
+		return c
+	}
+
+	t.Run("full diff", func(t *testing.T) {
+		report := NewReport("app")
+		ok := compareWithExpected(context.Background(), logrus.New(), hashC(), prefix, expected(), true, report)
+		assert.True(t, ok)
+		assert.Equal(t, 3, report.Checked)
+		assert.Equal(t, []*IntegrityError{
+			{Type: ErrTypeFileMismatch, Path: "bin/changed", Expected: "2", Actual: "x"},
+			{Type: ErrTypeNewFile, Path: "bin/new", Actual: "3"},
+			{Type: ErrTypeFileDeleted, Path: "bin/gone", Expected: "4"},
+			{Type: ErrTypeFileDeleted, Path: "bin/gone2", Expected: "5"},
+		}, report.Violations)
+		assert.Equal(t, IntegrityMessageMultipleErrs, report.Reason())
+		assert.Len(t, report.ByType(ErrTypeFileDeleted), 2)
+		assert.Equal(t, "4 integrity violation(s) (1 file content mismatch, 1 new file found, 2 file deleted): "+
+			"bin/changed,bin/new,bin/gone,bin/gone2", report.Error())
+	})
+
+	t.Run("stop on first violation", func(t *testing.T) {
+		report := NewReport("app")
+		ok := compareWithExpected(context.Background(), logrus.New(), hashC(), prefix, expected(), false, report)
+		assert.True(t, ok)
+		assert.Len(t, report.Violations, 1)
+		assert.Equal(t, IntegrityMessageFileMismatch, report.Reason())
+	})
+
+	t.Run("no violations", func(t *testing.T) {
+		report := NewReport("app")
+		ok := compareWithExpected(context.Background(), logrus.New(), hashC(), prefix,
+			expectedMap(map[string]string{"bin/ok": "1", "bin/changed": "x", "bin/new": "3"}), true, report)
+		assert.True(t, ok)
+		assert.False(t, report.HasViolations())
+		assert.Equal(t, "", report.Reason())
+	})
+}
+
+func TestCompareMetadata(t *testing.T) {
+	const prefix = "/proc/1/root/"
+	hashC := make(chan worker.FileHash, 3)
+	hashC <- worker.FileHash{Path: prefix + "bin/suid", Hash: "1", Meta: &data.FileMeta{Mode: 04755, Size: 10}}
+	hashC <- worker.FileHash{Path: prefix + "bin/ok", Hash: "2", Meta: &data.FileMeta{Mode: 0755, Size: 10}}
+	hashC <- worker.FileHash{Path: prefix + "bin/nometa", Hash: "3", Meta: &data.FileMeta{Mode: 0600}}
+	close(hashC)
+
+	expected := map[string]*data.HashDataOutput{
+		"bin/suid":   {Hash: "1", Meta: &data.FileMeta{Mode: 0755, Size: 10}},
+		"bin/ok":     {Hash: "2", Meta: &data.FileMeta{Mode: 0755, Size: 10}},
+		"bin/nometa": {Hash: "3"},
+	}
+	report := NewReport("app")
+	assert.True(t, compareWithExpected(context.Background(), logrus.New(), hashC, prefix, expected, true, report))
+	assert.Equal(t, []*IntegrityError{
+		{Type: ErrTypeMetadataMismatch, Path: "bin/suid", Expected: "mode=755", Actual: "mode=4755"},
+	}, report.Violations)
+	assert.Equal(t, IntegrityMessageMetadataMismatch, report.Reason())
+}
+
+func TestCompareNonRegular(t *testing.T) {
+	const prefix = "/proc/1/root/"
+	actual := []worker.FileHash{
+		{Path: prefix + "usr/bin", Hash: data.NoHash, Type: data.TypeDir},
+		{Path: prefix + "usr/bin/python", Hash: data.NoHash, Type: data.TypeSymlink, Target: "/tmp/evil"},
+		{Path: prefix + "usr/bin/sh", Hash: data.NoHash, Type: data.TypeSymlink, Target: "busybox"},
+		{Path: prefix + "usr/bin/busybox", Hash: "1"},
+		{Path: prefix + "usr/bin/tool", Hash: data.NoHash, Type: data.TypeDir},
+		{Path: prefix + "usr/bin/mem", Hash: data.NoHash, Type: data.TypeCharDev, Target: "1:1"},
+		{Path: prefix + "usr/bin/null", Hash: data.NoHash, Type: data.TypeCharDev, Target: "1:1"},
+	}
+	hashC := func() <-chan worker.FileHash {
+		c := make(chan worker.FileHash, len(actual))
+		for _, v := range actual {
+			c <- v
+		}
+		close(c)
+		return c
+	}
+
+	t.Run("tracked", func(t *testing.T) {
+		expected := map[string]*data.HashDataOutput{
+			"usr/bin":         {Hash: data.NoHash, Type: data.TypeDir},
+			"usr/bin/python":  {Hash: data.NoHash, Type: data.TypeSymlink, Target: "/usr/bin/python3"},
+			"usr/bin/sh":      {Hash: data.NoHash, Type: data.TypeSymlink, Target: "busybox"},
+			"usr/bin/busybox": {Hash: "1"},
+			"usr/bin/null":    {Hash: data.NoHash, Type: data.TypeCharDev, Target: "1:3"},
+			"usr/bin/old":     {Hash: data.NoHash, Type: data.TypeDir},
+		}
+		report := NewReport("app")
+		assert.True(t, compareWithExpected(context.Background(), logrus.New(), hashC(), prefix, expected, true, report))
+		assert.Equal(t, []*IntegrityError{
+			{Type: ErrTypeSymlinkMismatch, Path: "usr/bin/python", Expected: "/usr/bin/python3", Actual: "/tmp/evil"},
+			{Type: ErrTypeDirAdded, Path: "usr/bin/tool", Actual: "dir"},
+			{Type: ErrTypeNewSpecialFile, Path: "usr/bin/mem", Actual: "char:1:1"},
+			{Type: ErrTypeFileMismatch, Path: "usr/bin/null", Expected: "char:1:3", Actual: "char:1:1"},
+			{Type: ErrTypeDirDeleted, Path: "usr/bin/old", Expected: "dir"},
+		}, report.Violations)
+	})
+
+	t.Run("legacy snapshot", func(t *testing.T) {
+		report := NewReport("app")
+		assert.True(t, compareWithExpected(context.Background(), logrus.New(), hashC(), prefix,
+			expectedMap(map[string]string{"usr/bin/busybox": "1"}), true, report))
+		assert.False(t, report.HasViolations())
+		assert.Equal(t, 1, report.Checked)
+	})
+}
+
+func TestCompareOversize(t *testing.T) {
+	const prefix = "/proc/1/root/"
+	hashC := make(chan worker.FileHash, 3)
+	hashC <- worker.FileHash{Path: prefix + "data/skipped", Hash: data.NoHash, Oversize: worker.OversizeSkip}
+	hashC <- worker.FileHash{Path: prefix + "data/large", Hash: data.NoHash, Oversize: worker.OversizeViolation,
+		Meta: &data.FileMeta{Size: 100}}
+	hashC <- worker.FileHash{Path: prefix + "data/unlimited", Hash: "3"}
+	close(hashC)
+
+	expected := expectedMap(map[string]string{
+		"data/skipped":   "1",
+		"data/large":     "2",
+		"data/unlimited": data.NoHash,
+	})
+	report := NewReport("app")
+	assert.True(t, compareWithExpected(context.Background(), logrus.New(), hashC, prefix, expected, true, report))
+	assert.Equal(t, []*IntegrityError{
+		{Type: ErrTypeOversizeFile, Path: "data/large", Actual: "100"},
+	}, report.Violations)
+	assert.Equal(t, IntegrityMessageOversizeFile, report.Reason())
+}
@@ -0,0 +1,123 @@
+package integritymonitor
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/spf13/viper"
+
+	"github.com/ScienceSoft-Inc/integrity-sum/pkg/k8s"
+)
+
+// Response actions which may be selected for a monitored process
+const (
+	ActionAlert       = "alert"
+	ActionDelete      = "delete"
+	ActionEvict       = "evict"
+	ActionScaleToZero = "scale-to-zero"
+	ActionQuarantine  = "quarantine"
+	ActionRollback    = "rollback"
+	ActionRestore     = "restore"
+)
+
+// Responder performs a response action when integrity violations are found.
+type Responder interface {
+	// Action returns the name of the response action
+	Action() string
+	// Message returns the alert message describing the action for @podName
+	Message(podName string) string
+	Respond(ctx context.Context, report *Report) error
+}
+
+type responder struct {
+	action  string
+	message string
+	respond func(ctx context.Context) error
+}
+
+func (r *responder) Action() string { return r.action }
+
+func (r *responder) Message(podName string) string { return fmt.Sprintf(r.message, podName) }
+
+func (r *responder) Respond(ctx context.Context, _ *Report) error {
+	if r.respond == nil {
+		return nil
+	}
+	return r.respond(ctx)
+}
+
+// ResponderFactory creates a Responder using the kubernetes client.
+type ResponderFactory func(kubeClient k8s.IKuberService) Responder
+
+var responders = map[string]ResponderFactory{
+	ActionAlert: func(_ k8s.IKuberService) Responder {
+		return &responder{action: ActionAlert, message: "Integrity violation in pod %v"}
+	},
+	ActionDelete: func(kc k8s.IKuberService) Responder {
+		return &responder{action: ActionDelete, message: "Restart pod %v", respond: kc.RestartPod}
+	},
+	ActionEvict: func(kc k8s.IKuberService) Responder {
+		return &responder{action: ActionEvict, message: "Evict pod %v", respond: kc.EvictPod}
+	},
+	ActionScaleToZero: func(kc k8s.IKuberService) Responder {
+		return &responder{action: ActionScaleToZero, message: "Scale to zero owner of pod %v", respond: kc.ScaleOwnerToZero}
+	},
+	ActionQuarantine: func(kc k8s.IKuberService) Responder {
+		return &responder{action: ActionQuarantine, message: "Quarantine pod %v", respond: kc.QuarantinePod}
+	},
+	ActionRollback: func(kc k8s.IKuberService) Responder {
+		return &responder{action: ActionRollback, message: "Roll back deployment of pod %v", respond: kc.RollbackDeployment}
+	},
+	ActionRestore: newRestoreResponder,
+}
+
+// RegisterResponder registers a response action with the @action name.
+func RegisterResponder(action string, f ResponderFactory) {
+	responders[strings.ToLower(action)] = f
+}
+
+// ResponseAction returns the response action configured for the @procName
+// process, the default action is used if the process has no own one.
+func ResponseAction(procName string) string {
+	if action, ok := viper.GetStringMapString("response-action")[procName]; ok {
+		return strings.ToLower(action)
+	}
+	return strings.ToLower(viper.GetString("default-response-action"))
+}
+
+// NewResponder returns the Responder configured for the @procName process.
+func NewResponder(procName string, kubeClient k8s.IKuberService) (Responder, error) {
+	action := ResponseAction(procName)
+	f, ok := responders[action]
+	if !ok {
+		return nil, errUnknownAction(action, procName)
+	}
+	return f(kubeClient), nil
+}
+
+// ValidateResponseActions verifies that all configured response actions are
+// known.
+func ValidateResponseActions(procNames []string) error {
+	for _, p := range procNames {
+		if action := ResponseAction(p); responders[action] == nil {
+			return errUnknownAction(action, p)
+		}
+	}
+	return nil
+}
+
+func errUnknownAction(action, procName string) error {
+	return fmt.Errorf("unknown response action %q for process %s, available: %s",
+		action, procName, strings.Join(availableActions(), ","))
+}
+
+func availableActions() []string {
+	actions := make([]string, 0, len(responders))
+	for k := range responders {
+		actions = append(actions, k)
+	}
+	sort.Strings(actions)
+	return actions
+}
@@ -0,0 +1,66 @@
+package integritymonitor
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+
+	mockk8s "github.com/ScienceSoft-Inc/integrity-sum/pkg/k8s/mocks"
+)
+
+func TestNewResponder(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	kc := mockk8s.NewMockIKuberService(ctrl)
+
+	viper.Set("default-response-action", ActionDelete)
+	viper.Set("response-action", map[string]string{
+		"nginx": ActionEvict,
+		"redis": "QUARANTINE",
+		"app":   ActionAlert,
+		"db":    ActionScaleToZero,
+		"web":   ActionRollback,
+		"bad":   "unknown",
+	})
+	defer func() {
+		viper.Set("default-response-action", nil)
+		viper.Set("response-action", nil)
+	}()
+
+	testErr := errors.New("failed")
+	kc.EXPECT().EvictPod(gomock.Any()).Return(testErr)
+	kc.EXPECT().QuarantinePod(gomock.Any()).Return(nil)
+	kc.EXPECT().ScaleOwnerToZero(gomock.Any()).Return(nil)
+	kc.EXPECT().RestartPod(gomock.Any()).Return(nil)
+	kc.EXPECT().RollbackDeployment(gomock.Any()).Return(nil)
+
+	tests := []struct {
+		proc    string
+		action  string
+		wantErr error
+	}{
+		{proc: "nginx", action: ActionEvict, wantErr: testErr},
+		{proc: "redis", action: ActionQuarantine},
+		{proc: "app", action: ActionAlert},
+		{proc: "db", action: ActionScaleToZero},
+		{proc: "web", action: ActionRollback},
+		{proc: "other", action: ActionDelete},
+	}
+	for _, tt := range tests {
+		t.Run(tt.proc, func(t *testing.T) {
+			r, err := NewResponder(tt.proc, kc)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.action, r.Action())
+			assert.Equal(t, tt.wantErr, r.Respond(context.Background(), NewReport(tt.proc)))
+		})
+	}
+
+	_, err := NewResponder("bad", kc)
+	assert.Error(t, err)
+	assert.Error(t, ValidateResponseActions([]string{"nginx", "bad"}))
+	assert.NoError(t, ValidateResponseActions([]string{"nginx", "other"}))
+}
@@ -0,0 +1,108 @@
+package integritymonitor
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+
+	"github.com/ScienceSoft-Inc/integrity-sum/internal/data"
+	"github.com/ScienceSoft-Inc/integrity-sum/pkg/alerts"
+	"github.com/ScienceSoft-Inc/integrity-sum/pkg/hasher"
+	"github.com/ScienceSoft-Inc/integrity-sum/pkg/k8s"
+	"github.com/ScienceSoft-Inc/integrity-sum/pkg/minio"
+)
+
+// restoreResponder writes the original content of the tampered files back
+// from the golden store. The pod is deleted if any file can not be restored.
+type restoreResponder struct {
+	kubeClient k8s.IKuberService
+	// fetch returns the reader of the golden copy of the file with the hash
+	fetch func(ctx context.Context, hash string) (io.ReadCloser, error)
+}
+
+func newRestoreResponder(kc k8s.IKuberService) Responder {
+	return &restoreResponder{kubeClient: kc, fetch: fetchGoldenCopy}
+}
+
+func (r *restoreResponder) Action() string { return ActionRestore }
+
+func (r *restoreResponder) Message(podName string) string {
+	return fmt.Sprintf("Restore files in pod %v", podName)
+}
+
+func (r *restoreResponder) Respond(ctx context.Context, report *Report) error {
+	podName := report.Pod
+	alg := viper.GetString("algorithm")
+	expected, err := loadExpectedHashes(ctx, logrus.StandardLogger(), report.ProcessName, alg)
+	if err == nil {
+		err = r.restore(ctx, report, expected, alg)
+	}
+	if err != nil {
+		alertErr := alerts.Send(ctx, alerts.New(fmt.Sprintf("Restore failed in pod %v, restart pod: %v", podName, err),
+			IntegrityMessageRestoreFailed,
+			strings.Join(report.Paths(), ","),
+			report.ProcessName,
+		))
+		if alertErr != nil {
+			logrus.WithError(alertErr).Error("Failed send alert")
+		}
+		return r.kubeClient.RestartPod(ctx)
+	}
+
+	alertErr := alerts.Send(ctx, alerts.New(fmt.Sprintf("Restored %d files in pod %v", len(report.Violations), podName),
+		IntegrityMessageFilesRestored,
+		strings.Join(report.Paths(), ","),
+		report.ProcessName,
+	))
+	if alertErr != nil {
+		logrus.WithError(alertErr).Error("Failed send alert")
+	}
+	return nil
+}
+
+// restore writes the golden copies of the files of all the @report violations
+// and verifies them. Only the changed and deleted regular files can be
+// restored.
+func (r *restoreResponder) restore(ctx context.Context, report *Report, expected map[string]*data.HashDataOutput, alg string) error {
+	for _, v := range report.Violations {
+		root := report.RootOf(v)
+		if root == "" {
+			return fmt.Errorf("file system of the process is unknown")
+		}
+		if v.Type != ErrTypeFileMismatch && v.Type != ErrTypeFileDeleted {
+			return fmt.Errorf("%s: %s can not be restored", v.Path, v.Error())
+		}
+		h, ok := expected[v.Path]
+		if !ok || h.Type != "" || h.Hash == data.NoHash {
+			return fmt.Errorf("%s: not a regular file in the snapshot", v.Path)
+		}
+		if err := r.restoreFile(ctx, root, strings.TrimPrefix(v.Path, "/"), h, alg); err != nil {
+			return fmt.Errorf("%s: %w", v.Path, err)
+		}
+	}
+	return nil
+}
+
+// copyGolden writes the golden copy of the file with the @hash into @w,
+// returns the hash of the written content.
+func (r *restoreResponder) copyGolden(ctx context.Context, w io.Writer, hash, alg string) (string, error) {
+	golden, err := r.fetch(ctx, hash)
+	if err != nil {
+		return "", err
+	}
+	defer golden.Close()
+	return hasher.NewFileHasher(alg, logrus.StandardLogger()).HashData(io.TeeReader(golden, w))
+}
+
+// fetchGoldenCopy opens the file with the @hash in the golden store.
+func fetchGoldenCopy(ctx context.Context, hash string) (io.ReadCloser, error) {
+	bucket := viper.GetString("golden-bucket")
+	if bucket == "" {
+		return nil, fmt.Errorf("golden store is not configured")
+	}
+	return minio.Instance().Open(ctx, bucket, GoldenObject(viper.GetString("algorithm"), hash))
+}
@@ -0,0 +1,86 @@
+package integritymonitor
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"golang.org/x/sys/unix"
+
+	"github.com/ScienceSoft-Inc/integrity-sum/internal/data"
+	"github.com/ScienceSoft-Inc/integrity-sum/pkg/hasher"
+)
+
+// restoreFile replaces the @path file in the @root file system view with its
+// golden copy and verifies it against the @expected hash. The @path is
+// resolved inside the @root without following symlinks, so a tampered link can
+// not redirect the restore out of the container.
+func (r *restoreResponder) restoreFile(ctx context.Context, root, path string, expected *data.HashDataOutput, alg string) error {
+	dirFd, err := openDirInRoot(root, filepath.Dir(path))
+	if err != nil {
+		return err
+	}
+	defer unix.Close(dirFd)
+	name := filepath.Base(path)
+
+	// the snapshot metadata takes precedence over the tampered file one
+	mode := uint32(0o644)
+	uid, gid := -1, -1
+	var st unix.Stat_t
+	if err := unix.Fstatat(dirFd, name, &st, unix.AT_SYMLINK_NOFOLLOW); err == nil && st.Mode&unix.S_IFMT == unix.S_IFREG {
+		mode, uid, gid = st.Mode&0o7777, int(st.Uid), int(st.Gid)
+	}
+	if expected.Meta != nil {
+		mode, uid, gid = expected.Meta.Mode, int(expected.Meta.Uid), int(expected.Meta.Gid)
+	}
+
+	// the file is replaced atomically, so the process never reads it partially
+	tmpName := "." + name + ".restore-" + strconv.FormatInt(time.Now().UnixNano(), 36)
+	fd, err := unix.Openat(dirFd, tmpName, unix.O_WRONLY|unix.O_CREAT|unix.O_EXCL|unix.O_NOFOLLOW|unix.O_CLOEXEC, 0o600)
+	if err != nil {
+		return &os.PathError{Op: "create", Path: tmpName, Err: err}
+	}
+	defer unix.Unlinkat(dirFd, tmpName, 0)
+	tmp := os.NewFile(uintptr(fd), tmpName)
+	// the golden copy is streamed into the file and verified before the file
+	// is replaced
+	hash, err := r.copyGolden(ctx, tmp, expected.Hash, alg)
+	if err == nil {
+		// chown clears the setuid and setgid bits, so it goes first
+		err = unix.Fchown(fd, uid, gid)
+	}
+	if err == nil {
+		err = unix.Fchmod(fd, mode)
+	}
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+	if hash != expected.Hash {
+		return fmt.Errorf("golden copy hash mismatch")
+	}
+	if err := unix.Renameat(dirFd, tmpName, dirFd, name); err != nil {
+		return &os.LinkError{Op: "rename", Old: tmpName, New: name, Err: err}
+	}
+
+	fd, err = unix.Openat(dirFd, name, unix.O_RDONLY|unix.O_NOFOLLOW|unix.O_CLOEXEC, 0)
+	if err != nil {
+		return &os.PathError{Op: "open", Path: name, Err: err}
+	}
+	restored := os.NewFile(uintptr(fd), name)
+	defer restored.Close()
+	actual, err := hasher.NewFileHasher(alg, logrus.StandardLogger()).HashData(restored)
+	if err != nil {
+		return err
+	}
+	if actual != expected.Hash {
+		return fmt.Errorf("restored file hash mismatch")
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+package integritymonitor
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ScienceSoft-Inc/integrity-sum/internal/data"
+	"github.com/ScienceSoft-Inc/integrity-sum/pkg/hasher"
+)
+
+func TestRestoreFiles(t *testing.T) {
+	root := t.TempDir() + "/"
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "etc"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "etc", "app.conf"), []byte("tampered"), 0o600))
+
+	hashOf := func(s string) string {
+		h, err := hasher.NewFileHasher("sha256", logrus.New()).HashData(strings.NewReader(s))
+		require.NoError(t, err)
+		return h
+	}
+	golden := map[string][]byte{
+		hashOf("original"): []byte("original"),
+		hashOf("deleted"):  []byte("deleted"),
+		hashOf("other"):    []byte("forged"),
+	}
+	r := &restoreResponder{fetch: func(_ context.Context, hash string) (io.ReadCloser, error) {
+		if content, ok := golden[hash]; ok {
+			return io.NopCloser(bytes.NewReader(content)), nil
+		}
+		return nil, errors.New("not found")
+	}}
+
+	expected := map[string]*data.HashDataOutput{
+		"etc/app.conf": {Hash: hashOf("original")},
+		"etc/gone":     {Hash: hashOf("deleted"), Meta: &data.FileMeta{Mode: 0o640, Uid: uint32(os.Getuid()), Gid: uint32(os.Getgid())}},
+		"etc/forged":   {Hash: hashOf("other")},
+		"etc/missing":  {Hash: hashOf("missing")},
+		"etc/dir":      {Hash: data.NoHash, Type: data.TypeDir},
+	}
+	report := NewReport("app")
+	report.Root = root
+	report.add(&IntegrityError{Type: ErrTypeFileMismatch, Path: "etc/app.conf"})
+	report.add(&IntegrityError{Type: ErrTypeFileDeleted, Path: "etc/gone"})
+	require.NoError(t, r.restore(context.Background(), report, expected, "sha256"))
+
+	content, err := os.ReadFile(filepath.Join(root, "etc", "app.conf"))
+	require.NoError(t, err)
+	assert.Equal(t, "original", string(content))
+	fi, err := os.Stat(filepath.Join(root, "etc", "app.conf"))
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), fi.Mode().Perm())
+	fi, err = os.Stat(filepath.Join(root, "etc", "gone"))
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o640), fi.Mode().Perm())
+
+	for _, v := range []*IntegrityError{
+		{Type: ErrTypeFileMismatch, Path: "etc/forged"},
+		{Type: ErrTypeFileDeleted, Path: "etc/missing"},
+		{Type: ErrTypeDirDeleted, Path: "etc/dir"},
+		{Type: ErrTypeNewFile, Path: "etc/new"},
+	} {
+		report = NewReport("app")
+		report.Root = root
+		report.add(v)
+		assert.Error(t, r.restore(context.Background(), report, expected, "sha256"), v.Path)
+	}
+	_, err = os.Stat(filepath.Join(root, "etc", "forged"))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestRestoreFileSymlinks(t *testing.T) {
+	root := t.TempDir() + "/"
+	outside := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(outside, "app.conf"), []byte("outside"), 0o600))
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "etc"), 0o755))
+	// the tampered parent directory points out of the root
+	require.NoError(t, os.Symlink(outside, filepath.Join(root, "conf")))
+	// the tampered file points out of the root
+	require.NoError(t, os.Symlink(filepath.Join(outside, "app.conf"), filepath.Join(root, "etc", "app.conf")))
+
+	hash, err := hasher.NewFileHasher("sha256", logrus.New()).HashData(strings.NewReader("original"))
+	require.NoError(t, err)
+	r := &restoreResponder{fetch: func(_ context.Context, _ string) (io.ReadCloser, error) {
+		return io.NopCloser(strings.NewReader("original")), nil
+	}}
+	expected := &data.HashDataOutput{Hash: hash}
+
+	assert.Error(t, r.restoreFile(context.Background(), root, "conf/app.conf", expected, "sha256"))
+
+	// the link itself is replaced
+	require.NoError(t, r.restoreFile(context.Background(), root, "etc/app.conf", expected, "sha256"))
+	fi, err := os.Lstat(filepath.Join(root, "etc", "app.conf"))
+	require.NoError(t, err)
+	assert.True(t, fi.Mode().IsRegular())
+
+	content, err := os.ReadFile(filepath.Join(outside, "app.conf"))
+	require.NoError(t, err)
+	assert.Equal(t, "outside", string(content))
+	entries, err := os.ReadDir(outside)
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+}
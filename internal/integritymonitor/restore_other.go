@@ -0,0 +1,15 @@
+//go:build !linux
+
+package integritymonitor
+
+import (
+	"context"
+	"errors"
+
+	"github.com/ScienceSoft-Inc/integrity-sum/internal/data"
+)
+
+// restoreFile is supported on linux only.
+func (r *restoreResponder) restoreFile(_ context.Context, _, _ string, _ *data.HashDataOutput, _ string) error {
+	return errors.New("restoring files is supported on linux only")
+}
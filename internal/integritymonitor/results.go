@@ -0,0 +1,86 @@
+package integritymonitor
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// Scan statuses
+const (
+	ScanOK        = "ok"
+	ScanViolation = "violation"
+	ScanError     = "error"
+)
+
+// ScanResult is the outcome of a full scan of a process.
+type ScanResult struct {
+	Process    string            `json:"process"`
+	Started    time.Time         `json:"started"`
+	Duration   string            `json:"duration"`
+	Status     string            `json:"status"`
+	Audit      bool              `json:"audit,omitempty"`
+	Error      string            `json:"error,omitempty"`
+	Violations []*IntegrityError `json:"violations,omitempty"`
+}
+
+var (
+	resultsMu sync.Mutex
+	results   = make(map[string][]ScanResult)
+)
+
+// recordResult adds the result of the scan of the @procName process started
+// at @started and finished with @err into the history. The history keeps the
+// last "scan-history" results of every process.
+func recordResult(procName string, started time.Time, err error) ScanResult {
+	res := ScanResult{
+		Process:  procName,
+		Started:  started,
+		Duration: time.Since(started).Round(time.Millisecond).String(),
+		Status:   ScanOK,
+		Audit:    AuditMode(procName),
+	}
+	var report *Report
+	switch {
+	case errors.As(err, &report):
+		res.Status = ScanViolation
+		res.Violations = report.Violations
+	case err != nil:
+		res.Status = ScanError
+		res.Error = err.Error()
+	}
+
+	limit := viper.GetInt("scan-history")
+	if limit < 1 {
+		limit = 1
+	}
+	resultsMu.Lock()
+	defer resultsMu.Unlock()
+	h := append(results[procName], res)
+	if len(h) > limit {
+		h = append([]ScanResult(nil), h[len(h)-limit:]...)
+	}
+	results[procName] = h
+	return res
+}
+
+// LastResults returns the last scan result of every scanned process.
+func LastResults() map[string]ScanResult {
+	resultsMu.Lock()
+	defer resultsMu.Unlock()
+	res := make(map[string]ScanResult, len(results))
+	for proc, h := range results {
+		res[proc] = h[len(h)-1]
+	}
+	return res
+}
+
+// History returns the scan results of the @procName process, the oldest
+// first.
+func History(procName string) []ScanResult {
+	resultsMu.Lock()
+	defer resultsMu.Unlock()
+	return append([]ScanResult{}, results[procName]...)
+}
@@ -0,0 +1,36 @@
+package integritymonitor
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordResult(t *testing.T) {
+	viper.Set("scan-history", 2)
+	defer viper.Set("scan-history", nil)
+
+	report := NewReport("results")
+	report.add(&IntegrityError{Type: ErrTypeFileMismatch, Path: "etc/a", Expected: "1", Actual: "2"})
+
+	recordResult("results", time.Now(), nil)
+	recordResult("results", time.Now(), errors.New("no such process"))
+	recordResult("results", time.Now(), report)
+
+	history := History("results")
+	require.Len(t, history, 2)
+	assert.Equal(t, ScanError, history[0].Status)
+	assert.Equal(t, "no such process", history[0].Error)
+	assert.Equal(t, ScanViolation, history[1].Status)
+	assert.Equal(t, history[1], LastResults()["results"])
+	assert.Empty(t, History("unknown"))
+
+	data, err := json.Marshal(history[1].Violations)
+	require.NoError(t, err)
+	assert.JSONEq(t, `[{"reason":"file content mismatch","path":"etc/a","expected":"1","actual":"2"}]`, string(data))
+}
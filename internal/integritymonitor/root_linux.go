@@ -0,0 +1,57 @@
+package integritymonitor
+
+import (
+	"fmt"
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// resolveInRoot makes openat2 resolve the paths as if the root descriptor is
+// the file system root without following any symlink, so a tampered path can
+// not lead out of the process file system view.
+const resolveInRoot = unix.RESOLVE_IN_ROOT | unix.RESOLVE_NO_SYMLINKS | unix.RESOLVE_NO_MAGICLINKS
+
+// openDirInRoot opens the @dir directory of the @root file system view as if
+// @root is the file system root, the symlinks are not followed. Returns the
+// O_PATH descriptor for the *at calls.
+func openDirInRoot(root, dir string) (int, error) {
+	return openInRoot(root, dir, unix.O_PATH|unix.O_DIRECTORY|unix.O_CLOEXEC)
+}
+
+// openFileInRoot opens the regular file @p of the @root file system view for
+// reading as if @root is the file system root, the symlinks are not followed.
+// Fails if openat2 is not supported by the kernel.
+func openFileInRoot(root, p string) (*os.File, error) {
+	// O_NONBLOCK keeps a fifo planted in place of the file from blocking
+	fd, err := openInRoot(root, p, unix.O_RDONLY|unix.O_NOFOLLOW|unix.O_NONBLOCK|unix.O_CLOEXEC)
+	if err != nil {
+		return nil, err
+	}
+	f := os.NewFile(uintptr(fd), p)
+	fi, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	if !fi.Mode().IsRegular() {
+		f.Close()
+		return nil, fmt.Errorf("not a regular file")
+	}
+	return f, nil
+}
+
+// openInRoot opens the @p path of the @root file system view with the @flags
+// resolving it inside the @root.
+func openInRoot(root, p string, flags int) (int, error) {
+	rootFd, err := unix.Open(root, unix.O_PATH|unix.O_DIRECTORY|unix.O_CLOEXEC, 0)
+	if err != nil {
+		return -1, &os.PathError{Op: "open", Path: root, Err: err}
+	}
+	defer unix.Close(rootFd)
+	fd, err := unix.Openat2(rootFd, p, &unix.OpenHow{Flags: uint64(flags), Resolve: resolveInRoot})
+	if err != nil {
+		return -1, &os.PathError{Op: "openat2", Path: p, Err: err}
+	}
+	return fd, nil
+}
@@ -0,0 +1,14 @@
+//go:build !linux
+
+package integritymonitor
+
+import (
+	"errors"
+	"os"
+)
+
+// openFileInRoot is supported on linux only, the files of the process file
+// system view can not be opened safely without openat2.
+func openFileInRoot(_, _ string) (*os.File, error) {
+	return nil, errors.New("opening files in the process root is supported on linux only")
+}
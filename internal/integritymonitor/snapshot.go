@@ -2,15 +2,19 @@ package integritymonitor
 
 import (
 	"context"
+	"io"
 	"os"
+	"path/filepath"
 	"runtime"
 	"strings"
 
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/viper"
 
+	"github.com/ScienceSoft-Inc/integrity-sum/internal/data"
 	"github.com/ScienceSoft-Inc/integrity-sum/internal/walker"
 	"github.com/ScienceSoft-Inc/integrity-sum/internal/worker"
+	"github.com/ScienceSoft-Inc/integrity-sum/pkg/minio"
 )
 
 const DefaultHashSize = 128
@@ -42,8 +46,18 @@ func CalculateAndWriteHashes() error {
 		hashes = append(hashes, HashDir(rootPath, v, viper.GetString("algorithm"))...)
 	}
 
-	err = writeAsPlainText(file, hashes)
-	return err
+	err = writeAsPlainText(file, hashes, viper.GetBool("with-metadata"))
+	if err != nil || viper.GetString("golden-bucket") == "" {
+		return err
+	}
+
+	// the snapshot is kept even if the golden copies are not uploaded
+	if _, gerr := minio.NewStorage(logrus.StandardLogger()); gerr != nil {
+		return gerr
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), viper.GetDuration("scan-dir-timeout"))
+	defer cancel()
+	return uploadGoldenCopies(ctx, logrus.StandardLogger(), rootPath, hashes, viper.GetString("algorithm"))
 }
 
 // HashDir calculates file hashes of a given directory
@@ -54,21 +68,35 @@ func HashDir(rootPath, pathToMonitor, alg string) []worker.FileHash {
 	fileHachC := worker.WorkersPool(
 		runtime.NumCPU(),
 		walker.ChanWalkDir(ctx, []string{rootPath + pathToMonitor}, log),
-		worker.NewWorker(ctx, alg, log),
+		worker.NewWorker(ctx, alg, log, workerOptions()...),
 	)
 
 	hashes := make([]worker.FileHash, 0, DefaultHashSize)
 	for v := range fileHachC {
-		v.Path = strings.TrimPrefix(v.Path, rootPath)
+		v.Path = relativePath(rootPath, v.Path)
 		hashes = append(hashes, v)
 	}
 	return hashes
 }
 
-func writeAsPlainText(file *os.File, hashes []worker.FileHash) error {
-	separator := "  "
+// relativePath returns the @path found by the walker in the @root file system
+// relative to the @root. The walker cleans the paths, so the @root is cleaned
+// as well, e.g. "./rfs/app/f" is walked as "rfs/app/f".
+func relativePath(root, path string) string {
+	rel, err := filepath.Rel(filepath.Clean(root), path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, "../") {
+		return strings.TrimPrefix(path, root)
+	}
+	return rel
+}
+
+func writeAsPlainText(w io.Writer, hashes []worker.FileHash, withMeta bool) error {
 	for _, v := range hashes {
-		_, err := file.WriteString(v.Hash + separator + v.Path + "\n")
+		meta := v.Meta
+		if !withMeta {
+			meta = nil
+		}
+		_, err := io.WriteString(w, data.FormatRecord(v.Hash, v.Path, v.Type, v.Target, meta)+"\n")
 		if err != nil {
 			logrus.Errorf("failed to write hashes: %v", err)
 			return err
@@ -1,9 +1,11 @@
 package integritymonitor
 
 import (
+	"github.com/ScienceSoft-Inc/integrity-sum/internal/data"
 	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
 	"os"
+	"sort"
 	"testing"
 	"time"
 )
@@ -32,10 +34,63 @@ func TestHashDir(t *testing.T) {
 
 	// call HashDir and verify result
 	result := HashDir(rootPath, testDir, alg)
-	if len(result) != 1 {
+	if len(result) != 2 {
 		t.Fatalf("HashDir returned unexpected number of results: %d", len(result))
 	}
-	if result[0].Hash != testFileHash {
-		t.Fatalf("HashDir returned unexpected file hash: %s", result[0].Hash)
+	for _, v := range result {
+		switch v.Path {
+		case "fancydir":
+			if v.Type != data.TypeDir || v.Hash != data.NoHash {
+				t.Fatalf("HashDir returned unexpected directory record: %+v", v)
+			}
+		case "fancydir/fancyfile":
+			if v.Hash != testFileHash {
+				t.Fatalf("HashDir returned unexpected file hash: %s", v.Hash)
+			}
+		default:
+			t.Fatalf("HashDir returned unexpected path: %s", v.Path)
+		}
+	}
+}
+
+func TestHashDirRelativeRoot(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = os.Chdir(t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+
+	if err = os.MkdirAll("rfs/app", os.ModePerm); err != nil {
+		t.Fatalf("failed to create test directory: %v", err)
+	}
+	if err = os.WriteFile("rfs/app/f", nil, 0o644); err != nil {
+		t.Fatalf("failed to create test file: %v", err)
+	}
+
+	// --root-fs=./rfs
+	result := HashDir("./rfs/", "app", "sha256")
+	paths := make([]string, 0, len(result))
+	for _, v := range result {
+		paths = append(paths, v.Path)
+	}
+	sort.Strings(paths)
+	if len(paths) != 2 || paths[0] != "app" || paths[1] != "app/f" {
+		t.Fatalf("HashDir returned unexpected paths: %v", paths)
+	}
+}
+
+func TestRelativePath(t *testing.T) {
+	for _, tc := range []struct{ root, path, want string }{
+		{"./rfs/", "rfs/app/f", "app/f"},
+		{"rfs//", "rfs/app", "app"},
+		{"/proc/12/root/", "/proc/12/root/usr/bin/nginx", "usr/bin/nginx"},
+		{"/proc/12/root", "/proc/12/root/etc", "etc"},
+	} {
+		if got := relativePath(tc.root, tc.path); got != tc.want {
+			t.Errorf("relativePath(%q, %q) = %q, want %q", tc.root, tc.path, got, tc.want)
+		}
 	}
 }
@@ -0,0 +1,192 @@
+package integritymonitor
+
+import (
+	"context"
+	"errors"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+
+	"github.com/ScienceSoft-Inc/integrity-sum/internal/data"
+	"github.com/ScienceSoft-Inc/integrity-sum/internal/utils/process"
+	"github.com/ScienceSoft-Inc/integrity-sum/internal/watcher"
+	"github.com/ScienceSoft-Inc/integrity-sum/internal/worker"
+	"github.com/ScienceSoft-Inc/integrity-sum/pkg/k8s"
+)
+
+// ErrWatchStopped is returned by WatchIntegrity when the watched directories
+// are gone, e.g. the monitored process has been restarted.
+var ErrWatchStopped = errors.New("watching stopped")
+
+var (
+	procLocksMu sync.Mutex
+	procLocks   = make(map[string]*sync.Mutex)
+)
+
+// lockProcess serializes the checks of the @procName process, so the full
+// scan and the real-time checks do not report the same violation twice.
+func lockProcess(procName string) func() {
+	procLocksMu.Lock()
+	l, ok := procLocks[procName]
+	if !ok {
+		l = &sync.Mutex{}
+		procLocks[procName] = l
+	}
+	procLocksMu.Unlock()
+
+	l.Lock()
+	return l.Unlock
+}
+
+// WatchIntegrity verifies the files of all instances of the @processName
+// process as soon as they are changed. Only the changed files are rehashed, the
+// events are collected for the "watch-debounce" interval before the check. If
+// events have been lost the process name is sent into @rescanC to request the
+// full scan.
+func WatchIntegrity(ctx context.Context, log *logrus.Logger, processName string, monitoringDirectories []string,
+	deploymentData *k8s.DeploymentData, kubeClient k8s.IKuberService, rescanC chan<- string) error {
+	roots, err := process.Roots(processName)
+	if err != nil {
+		return err
+	}
+	paths := make([]string, 0, len(roots)*len(monitoringDirectories))
+	for _, root := range roots {
+		for _, p := range monitoringDirectories {
+			paths = append(paths, root+p)
+		}
+	}
+
+	eventC, err := watcher.Watch(ctx, log, paths)
+	if err != nil {
+		return err
+	}
+	log.WithField("process", processName).Info("real-time watching started")
+
+	debounce := viper.GetDuration("watch-debounce")
+	changed := make(map[string]struct{})
+	var timerC <-chan time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case e, ok := <-eventC:
+			if !ok {
+				if ctx.Err() != nil {
+					return ctx.Err()
+				}
+				return ErrWatchStopped
+			}
+			if e.Overflow {
+				// the full scan covers the collected changes as well
+				changed = make(map[string]struct{})
+				timerC = nil
+				select {
+				case rescanC <- processName:
+				default:
+					log.WithField("process", processName).Debug("full scan is already requested")
+				}
+				continue
+			}
+			changed[e.Path] = struct{}{}
+			if timerC == nil {
+				timerC = time.After(debounce)
+			}
+		case <-timerC:
+			timerC = nil
+			files := make(map[string][]string, len(roots))
+			for p := range changed {
+				for _, root := range roots {
+					if strings.HasPrefix(p, root) {
+						files[root] = append(files[root], p)
+						break
+					}
+				}
+			}
+			changed = make(map[string]struct{})
+
+			for _, root := range roots {
+				if len(files[root]) == 0 {
+					continue
+				}
+				err = checkChangedFiles(ctx, log, processName, root, files[root], deploymentData, kubeClient)
+				if err != nil {
+					log.WithError(err).WithField("process", processName).Error("real-time check failed")
+				}
+			}
+		}
+	}
+}
+
+// checkChangedFiles verifies the changed @files of the process against its
+// snapshot and runs the response action if any violation has been found.
+func checkChangedFiles(ctx context.Context, log *logrus.Logger, processName, prefix string, files []string,
+	deploymentData *k8s.DeploymentData, kubeClient k8s.IKuberService) error {
+	unlock := lockProcess(processName)
+	defer unlock()
+
+	algName := viper.GetString("algorithm")
+	expected, err := loadExpectedHashes(ctx, log, processName, algName)
+	if err != nil {
+		return err
+	}
+
+	report := NewReport(processName)
+	report.Root = prefix
+	trackNonRegular := tracksNonRegular(expected)
+	sort.Strings(files)
+	existing := make([]string, 0, len(files))
+	for _, f := range files {
+		if _, err := os.Lstat(f); err == nil {
+			existing = append(existing, f)
+			continue
+		}
+		reportGone(log, strings.TrimPrefix(f, prefix), expected, report)
+	}
+
+	fileNameC := make(chan string, len(existing))
+	for _, f := range existing {
+		fileNameC <- f
+	}
+	close(fileNameC)
+	hashC := worker.WorkersPool(viper.GetInt("count-workers"), fileNameC, worker.NewWorker(ctx, algName, log, workerOptions()...))
+	for v := range hashC {
+		verifyEntry(log, v, prefix, expected, trackNonRegular, report)
+	}
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	if report.HasViolations() {
+		integrityCheckFailed(ctx, log, report, deploymentData, kubeClient)
+		return report
+	}
+	log.WithField("countHashes", report.Checked).Debug("changed files verified successfully")
+	return nil
+}
+
+// reportGone adds the violations for the removed @path and, if it was a
+// directory moved away, for all the files it contained.
+func reportGone(log *logrus.Logger, path string, expected map[string]*data.HashDataOutput, report *Report) {
+	if h, ok := expected[path]; ok {
+		reportDeleted(log, path, h, report)
+	}
+	delete(expected, path)
+
+	dirPrefix := path + "/"
+	var nested []string
+	for p := range expected {
+		if strings.HasPrefix(p, dirPrefix) {
+			nested = append(nested, p)
+		}
+	}
+	sort.Strings(nested)
+	for _, p := range nested {
+		reportDeleted(log, p, expected[p], report)
+		delete(expected, p)
+	}
+}
@@ -0,0 +1,30 @@
+package integritymonitor
+
+import (
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ScienceSoft-Inc/integrity-sum/internal/data"
+)
+
+func TestReportGone(t *testing.T) {
+	expected := map[string]*data.HashDataOutput{
+		"app":          {Hash: data.NoHash, Type: data.TypeDir},
+		"app/bin":      {Hash: "1"},
+		"app/lib/a.so": {Hash: "2"},
+		"application":  {Hash: "3"},
+	}
+	report := NewReport("app")
+	reportGone(logrus.New(), "app", expected, report)
+	reportGone(logrus.New(), "app/bin", expected, report)
+	reportGone(logrus.New(), "unknown", expected, report)
+
+	assert.Equal(t, []*IntegrityError{
+		{Type: ErrTypeDirDeleted, Path: "app", Expected: "dir"},
+		{Type: ErrTypeFileDeleted, Path: "app/bin", Expected: "1"},
+		{Type: ErrTypeFileDeleted, Path: "app/lib/a.so", Expected: "2"},
+	}, report.Violations)
+	assert.Contains(t, expected, "application")
+}
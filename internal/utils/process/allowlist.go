@@ -0,0 +1,137 @@
+package process
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// AllowRule permits the processes running the executable matching the Exe
+// glob pattern and, if set, the Cmdline regular expression.
+type AllowRule struct {
+	Exe     string
+	Cmdline *regexp.Regexp
+}
+
+// Allowlist is the list of the processes permitted to run in a container.
+type Allowlist []AllowRule
+
+// ParseAllowlist parses the rules in the "exe[:cmdline][;exe[:cmdline]...]"
+// form, e.g. "/usr/sbin/nginx;/bin/sh:^sh -c /healthcheck\.sh$".
+func ParseAllowlist(spec string) (Allowlist, error) {
+	var a Allowlist
+	for _, r := range strings.Split(spec, ";") {
+		exe, cmdline, hasCmdline := strings.Cut(strings.TrimSpace(r), ":")
+		if !strings.HasPrefix(exe, "/") {
+			return nil, fmt.Errorf("incorrect allowlist rule %q, absolute executable path expected", r)
+		}
+		if _, err := filepath.Match(exe, ""); err != nil {
+			return nil, fmt.Errorf("incorrect executable pattern %q: %w", exe, err)
+		}
+		rule := AllowRule{Exe: exe}
+		if hasCmdline {
+			re, err := regexp.Compile(cmdline)
+			if err != nil {
+				return nil, fmt.Errorf("incorrect cmdline pattern %q: %w", cmdline, err)
+			}
+			rule.Cmdline = re
+		}
+		a = append(a, rule)
+	}
+	return a, nil
+}
+
+// Allows reports whether the process running the @exe with the @cmdline is
+// permitted.
+func (a Allowlist) Allows(exe, cmdline string) bool {
+	for _, r := range a {
+		if ok, _ := filepath.Match(r.Exe, exe); !ok {
+			continue
+		}
+		if r.Cmdline == nil || r.Cmdline.MatchString(cmdline) {
+			return true
+		}
+	}
+	return false
+}
+
+// UnknownExe is the executable of the processes whose /proc/<pid>/exe can not
+// be read, e.g. due to the changed dumpable state. It matches no allowlist rule.
+const UnknownExe = "<unknown>"
+
+// Info describes a running process.
+type Info struct {
+	PID     int
+	Exe     string
+	Cmdline string
+}
+
+// Namespaced returns all the processes sharing the mount namespace with any
+// of the @pids processes, i.e. running in the same containers. The processes
+// exited meanwhile and the zombies are skipped, the executable of the other
+// processes which can not be described is UnknownExe.
+func Namespaced(pids []int) ([]Info, error) {
+	namespaces := make(map[string]struct{}, len(pids))
+	for _, pid := range pids {
+		ns, err := MountNamespace(pid)
+		if err != nil {
+			return nil, fmt.Errorf("failed read mount namespace of %d: %w", pid, err)
+		}
+		namespaces[ns] = struct{}{}
+	}
+
+	dir := procDir()
+	all, err := listPIDs(dir)
+	if err != nil {
+		return nil, err
+	}
+	var res []Info
+	for _, pid := range all {
+		ns, err := MountNamespace(pid)
+		if err != nil {
+			continue
+		}
+		if _, ok := namespaces[ns]; !ok {
+			continue
+		}
+		info, err := Describe(pid)
+		if err != nil {
+			pidDir := filepath.Join(dir, strconv.Itoa(pid))
+			if _, serr := os.Stat(pidDir); serr != nil || isZombie(pidDir) {
+				continue
+			}
+			// the process can't hide from the allowlist by being unreadable
+			cmdline, _ := readCmdline(pidDir)
+			info = Info{PID: pid, Exe: UnknownExe, Cmdline: cmdline}
+		}
+		res = append(res, info)
+	}
+	return res, nil
+}
+
+// Describe returns the executable and the command line of the @pid process.
+func Describe(pid int) (Info, error) {
+	pidDir := filepath.Join(procDir(), strconv.Itoa(pid))
+	exe, err := os.Readlink(filepath.Join(pidDir, "exe"))
+	if err != nil {
+		return Info{}, err
+	}
+	cmdline, _ := readCmdline(pidDir)
+	return Info{PID: pid, Exe: strings.TrimSuffix(exe, deletedSuffix), Cmdline: cmdline}, nil
+}
+
+// isZombie reports whether the process of the @pidDir has exited but has not
+// been reaped yet, its executable is not known anymore.
+func isZombie(pidDir string) bool {
+	stat, err := os.ReadFile(filepath.Join(pidDir, "stat"))
+	if err != nil {
+		return false
+	}
+	// the state follows the command name which may contain any characters
+	i := bytes.LastIndexByte(stat, ')')
+	return i >= 0 && bytes.HasPrefix(stat[i+1:], []byte(" Z"))
+}
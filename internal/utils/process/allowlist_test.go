@@ -0,0 +1,64 @@
+package process
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAllowlist(t *testing.T) {
+	a, err := ParseAllowlist(`/usr/sbin/nginx;/usr/bin/python3*;/bin/sh:^sh -c /healthcheck\.sh$`)
+	require.NoError(t, err)
+
+	tests := []struct {
+		exe, cmdline string
+		want         bool
+	}{
+		{exe: "/usr/sbin/nginx", cmdline: "nginx: worker process", want: true},
+		{exe: "/usr/bin/python3.11", cmdline: "python3 app.py", want: true},
+		{exe: "/bin/sh", cmdline: "sh -c /healthcheck.sh", want: true},
+		{exe: "/bin/sh", cmdline: "sh -i"},
+		{exe: "/tmp/xmrig", cmdline: "xmrig -o pool"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, a.Allows(tt.exe, tt.cmdline), tt.exe+" "+tt.cmdline)
+	}
+
+	for _, spec := range []string{"", "nginx", "/bin/sh:(", "/bin/[:x"} {
+		_, err = ParseAllowlist(spec)
+		assert.Error(t, err, spec)
+	}
+}
+
+func TestNamespaced(t *testing.T) {
+	procDir := fakeProcDir(t, []fakeProc{
+		{pid: 10, comm: "nginx", exe: "/usr/sbin/nginx", cmdline: "nginx\x00", mntNS: "mnt:[1]"},
+		{pid: 11, comm: "sh", exe: "/bin/sh (deleted)", cmdline: "sh\x00-i\x00", mntNS: "mnt:[1]"},
+		{pid: 20, comm: "sidecar", exe: "/integritySum", cmdline: "/integritySum\x00", mntNS: "mnt:[2]"},
+		// the exe link of these can't be read
+		{pid: 12, comm: "xmrig", cmdline: "xmrig\x00", mntNS: "mnt:[1]"},
+		{pid: 13, comm: "sh", mntNS: "mnt:[1]"},
+	})
+	require.NoError(t, os.WriteFile(filepath.Join(procDir, "13", "stat"), []byte("13 (sh) Z 1 13 13 0 -1"), 0o644))
+	viper.Set("proc-dir", procDir)
+	defer viper.Set("proc-dir", "")
+
+	procs, err := Namespaced([]int{10})
+	require.NoError(t, err)
+	assert.Equal(t, []Info{
+		{PID: 10, Exe: "/usr/sbin/nginx", Cmdline: "nginx"},
+		{PID: 11, Exe: "/bin/sh", Cmdline: "sh -i"},
+		{PID: 12, Exe: UnknownExe, Cmdline: "xmrig"},
+	}, procs)
+
+	a, err := ParseAllowlist("/*")
+	require.NoError(t, err)
+	assert.False(t, a.Allows(procs[2].Exe, procs[2].Cmdline))
+
+	_, err = Describe(12)
+	assert.Error(t, err)
+}
@@ -0,0 +1,187 @@
+package process
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Kinds of the process match criteria
+const (
+	MatchComm    = "comm"    // process name from /proc/<pid>/comm
+	MatchExe     = "exe"     // executable path, or its base name if no slash
+	MatchCmdline = "cmdline" // regular expression for the command line
+	MatchCgroup  = "cgroup"  // substring of the cgroup, e.g. a container ID
+)
+
+// Matcher selects processes by their attributes, all the set criteria must
+// match. An empty Matcher matches nothing.
+type Matcher struct {
+	// Name matches either the comm or the executable base name, like pidof
+	Name    string
+	Comm    string
+	Exe     string
+	Cmdline *regexp.Regexp
+	Cgroup  string
+}
+
+// NameMatcher returns the matcher which behaves like pidof: the process name
+// is compared to the comm and to the base name of the executable.
+func NameMatcher(procName string) Matcher {
+	return Matcher{Name: procName}
+}
+
+// ParseMatcher parses the criteria in the "kind:value[;kind:value...]" form,
+// e.g. "exe:/usr/sbin/nginx;cgroup:3f4e2c".
+func ParseMatcher(spec string) (Matcher, error) {
+	var m Matcher
+	for _, c := range strings.Split(spec, ";") {
+		kind, value, ok := strings.Cut(strings.TrimSpace(c), ":")
+		if !ok || value == "" {
+			return Matcher{}, fmt.Errorf("incorrect match criterion %q, expected kind:value", c)
+		}
+		switch kind {
+		case MatchComm:
+			m.Comm = value
+		case MatchExe:
+			m.Exe = value
+		case MatchCmdline:
+			re, err := regexp.Compile(value)
+			if err != nil {
+				return Matcher{}, fmt.Errorf("incorrect cmdline pattern %q: %w", value, err)
+			}
+			m.Cmdline = re
+		case MatchCgroup:
+			m.Cgroup = value
+		default:
+			return Matcher{}, fmt.Errorf("unknown match criterion %q, available: %s, %s, %s, %s",
+				kind, MatchComm, MatchExe, MatchCmdline, MatchCgroup)
+		}
+	}
+	return m, nil
+}
+
+func (m Matcher) empty() bool {
+	return m.Name == "" && m.Comm == "" && m.Exe == "" && m.Cmdline == nil && m.Cgroup == ""
+}
+
+// Match reports whether the process with the @pid in the @procDir matches.
+func (m Matcher) Match(procDir string, pid int) bool {
+	if m.empty() {
+		return false
+	}
+	dir := filepath.Join(procDir, strconv.Itoa(pid))
+	if m.Name != "" && !matchComm(dir, m.Name) && !matchExe(dir, m.Name) {
+		return false
+	}
+	if m.Comm != "" && !matchComm(dir, m.Comm) {
+		return false
+	}
+	if m.Exe != "" && !matchExe(dir, m.Exe) {
+		return false
+	}
+	if m.Cmdline != nil && !m.matchCmdline(dir) {
+		return false
+	}
+	if m.Cgroup != "" && !m.matchCgroup(dir) {
+		return false
+	}
+	return true
+}
+
+func matchComm(dir, name string) bool {
+	comm, err := os.ReadFile(filepath.Join(dir, "comm"))
+	if err != nil {
+		return false
+	}
+	// comm is truncated by the kernel to 15 characters
+	if len(name) > 15 {
+		name = name[:15]
+	}
+	return strings.TrimSuffix(string(comm), "\n") == name
+}
+
+func matchExe(dir, name string) bool {
+	exe, err := os.Readlink(filepath.Join(dir, "exe"))
+	if err != nil {
+		return false
+	}
+	exe = strings.TrimSuffix(exe, " (deleted)")
+	if strings.Contains(name, "/") {
+		return exe == name
+	}
+	return filepath.Base(exe) == name
+}
+
+func (m Matcher) matchCmdline(dir string) bool {
+	cmdline, err := readCmdline(dir)
+	if err != nil || cmdline == "" {
+		return false
+	}
+	return m.Cmdline.MatchString(cmdline)
+}
+
+// readCmdline returns the command line of the process with the arguments
+// separated by spaces.
+func readCmdline(dir string) (string, error) {
+	cmdline, err := os.ReadFile(filepath.Join(dir, "cmdline"))
+	if err != nil {
+		return "", err
+	}
+	// arguments are separated with zero bytes
+	args := bytes.TrimSuffix(cmdline, []byte{0})
+	return string(bytes.ReplaceAll(args, []byte{0}, []byte{' '})), nil
+}
+
+func (m Matcher) matchCgroup(dir string) bool {
+	cgroup, err := os.ReadFile(filepath.Join(dir, "cgroup"))
+	if err != nil {
+		return false
+	}
+	return bytes.Contains(cgroup, []byte(m.Cgroup))
+}
+
+// FindPIDs returns the sorted PIDs of all the processes in the @procDir
+// matching the @m, the calling process is never returned.
+func FindPIDs(procDir string, m Matcher) ([]int, error) {
+	all, err := listPIDs(procDir)
+	if err != nil {
+		return nil, err
+	}
+	var pids []int
+	for _, pid := range all {
+		// processes may exit while scanning, they just do not match
+		if m.Match(procDir, pid) {
+			pids = append(pids, pid)
+		}
+	}
+	if len(pids) == 0 {
+		return nil, ErrProcessNotFound
+	}
+	return pids, nil
+}
+
+// listPIDs returns the sorted PIDs of all the processes in the @procDir except
+// the calling one.
+func listPIDs(procDir string) ([]int, error) {
+	entries, err := os.ReadDir(procDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed read %s: %w", procDir, err)
+	}
+	self := os.Getpid()
+	var pids []int
+	for _, e := range entries {
+		pid, err := strconv.Atoi(e.Name())
+		if err != nil || pid == self || !e.IsDir() {
+			continue
+		}
+		pids = append(pids, pid)
+	}
+	sort.Ints(pids)
+	return pids, nil
+}
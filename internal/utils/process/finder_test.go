@@ -0,0 +1,91 @@
+package process
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeProc struct {
+	pid     int
+	comm    string
+	exe     string
+	cmdline string
+	cgroup  string
+	mntNS   string
+}
+
+func fakeProcDir(t *testing.T, procs []fakeProc) string {
+	dir := t.TempDir()
+	for _, p := range procs {
+		pd := filepath.Join(dir, strconv.Itoa(p.pid))
+		require.NoError(t, os.Mkdir(pd, 0o755))
+		require.NoError(t, os.WriteFile(filepath.Join(pd, "comm"), []byte(p.comm+"\n"), 0o644))
+		require.NoError(t, os.WriteFile(filepath.Join(pd, "cmdline"), []byte(p.cmdline), 0o644))
+		require.NoError(t, os.WriteFile(filepath.Join(pd, "cgroup"), []byte(p.cgroup), 0o644))
+		if p.exe != "" {
+			require.NoError(t, os.Symlink(p.exe, filepath.Join(pd, "exe")))
+		}
+		if p.mntNS != "" {
+			require.NoError(t, os.Mkdir(filepath.Join(pd, "ns"), 0o755))
+			require.NoError(t, os.Symlink(p.mntNS, filepath.Join(pd, "ns", "mnt")))
+		}
+	}
+	// non-process entries are ignored
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "sys"), 0o755))
+	return dir
+}
+
+func TestFindPIDs(t *testing.T) {
+	procDir := fakeProcDir(t, []fakeProc{
+		{pid: 10, comm: "nginx", exe: "/usr/sbin/nginx", cmdline: "nginx: master process\x00", cgroup: "0::/kubepods/pod1/aaa\n"},
+		{pid: 11, comm: "nginx", exe: "/usr/sbin/nginx", cmdline: "nginx: worker process\x00", cgroup: "0::/kubepods/pod1/aaa\n"},
+		{pid: 20, comm: "python3", exe: "/usr/bin/python3.11", cmdline: "python3\x00/app/main.py\x00--port\x008080\x00", cgroup: "0::/kubepods/pod1/bbb\n"},
+		{pid: 30, comm: "sh", exe: "/bin/busybox (deleted)", cmdline: "sh\x00", cgroup: "0::/kubepods/pod1/bbb\n"},
+	})
+
+	tests := []struct {
+		name string
+		m    Matcher
+		want []int
+	}{
+		{name: "name by comm", m: NameMatcher("nginx"), want: []int{10, 11}},
+		{name: "name by exe", m: NameMatcher("python3.11"), want: []int{20}},
+		{name: "deleted exe", m: Matcher{Exe: "/bin/busybox"}, want: []int{30}},
+		{name: "exe path", m: Matcher{Exe: "/usr/sbin/nginx"}, want: []int{10, 11}},
+		{name: "cmdline", m: Matcher{Cmdline: regexp.MustCompile(`main\.py --port 8080$`)}, want: []int{20}},
+		{name: "cgroup and comm", m: Matcher{Comm: "sh", Cgroup: "bbb"}, want: []int{30}},
+		{name: "criteria combined", m: Matcher{Comm: "nginx", Cmdline: regexp.MustCompile("worker")}, want: []int{11}},
+		{name: "no match", m: Matcher{Comm: "nginx", Cgroup: "bbb"}},
+		{name: "empty", m: Matcher{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pids, err := FindPIDs(procDir, tt.m)
+			if tt.want == nil {
+				assert.ErrorIs(t, err, ErrProcessNotFound)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, pids)
+		})
+	}
+}
+
+func TestParseMatcher(t *testing.T) {
+	m, err := ParseMatcher("exe:/usr/sbin/nginx;cmdline:^nginx: (master|worker);cgroup:aaa")
+	require.NoError(t, err)
+	assert.Equal(t, "/usr/sbin/nginx", m.Exe)
+	assert.Equal(t, "aaa", m.Cgroup)
+	assert.True(t, m.Cmdline.MatchString("nginx: worker process"))
+
+	for _, spec := range []string{"", "nginx", "pid:1", "cmdline:(", "comm:"} {
+		_, err = ParseMatcher(spec)
+		assert.Error(t, err, spec)
+	}
+}
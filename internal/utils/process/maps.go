@@ -0,0 +1,88 @@
+package process
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+)
+
+const deletedSuffix = " (deleted)"
+
+// Executable is a file executed by a process: its executable or a file-backed
+// executable memory mapping, e.g. a shared library.
+type Executable struct {
+	// Path in the mount namespace of the process
+	Path string
+	// Deleted is set if the file has been deleted or replaced since mapped
+	Deleted bool
+}
+
+// Executables returns the executable and the executable file-backed mappings
+// of the @pid process, every path is returned once.
+func Executables(pid int) ([]Executable, error) {
+	dir := fmt.Sprintf("%s/%d", procDir(), pid)
+	exe, err := os.Readlink(dir + "/exe")
+	if err != nil {
+		return nil, fmt.Errorf("failed read executable of %d: %w", pid, err)
+	}
+	res := []Executable{newExecutable(exe)}
+	seen := map[string]struct{}{exe: {}}
+
+	f, err := os.Open(dir + "/maps")
+	if err != nil {
+		return nil, fmt.Errorf("failed read mappings of %d: %w", pid, err)
+	}
+	defer f.Close()
+
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		path, ok := parseMapping(s.Text())
+		if !ok {
+			continue
+		}
+		if _, ok = seen[path]; ok {
+			continue
+		}
+		seen[path] = struct{}{}
+		res = append(res, newExecutable(path))
+	}
+	if err = s.Err(); err != nil {
+		return nil, fmt.Errorf("failed read mappings of %d: %w", pid, err)
+	}
+	return res, nil
+}
+
+func newExecutable(path string) Executable {
+	return Executable{
+		Path:    strings.TrimSuffix(path, deletedSuffix),
+		Deleted: strings.HasSuffix(path, deletedSuffix),
+	}
+}
+
+// parseMapping returns the path of the file mapped as executable by the @line
+// of /proc/<pid>/maps: "address perms offset dev inode path".
+func parseMapping(line string) (string, bool) {
+	fields := strings.Fields(line)
+	if len(fields) < 6 || !strings.Contains(fields[1], "x") || fields[4] == "0" {
+		return "", false
+	}
+	path := strings.Join(fields[5:], " ")
+	// pseudo paths like [vdso] are not files
+	if !strings.HasPrefix(path, "/") {
+		return "", false
+	}
+	return path, true
+}
+
+// MountNamespace returns the mount namespace of the @pid process, processes
+// with the same namespace have the same file system view.
+func MountNamespace(pid int) (string, error) {
+	return os.Readlink(fmt.Sprintf("%s/%d/ns/mnt", procDir(), pid))
+}
+
+// Root returns the root file system path "<proc-dir>/<pid>/root/" of the @pid
+// process.
+func Root(pid int) string {
+	return fmt.Sprintf("%s/%d/root/", procDir(), pid)
+}
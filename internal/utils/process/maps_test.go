@@ -0,0 +1,39 @@
+package process
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExecutables(t *testing.T) {
+	procDir := fakeProcDir(t, []fakeProc{{pid: 10, comm: "app", exe: "/app/bin/app (deleted)"}})
+	maps := `55d0c0a00000-55d0c0a21000 r--p 00000000 08:01 1311 /app/bin/app (deleted)
+55d0c0a21000-55d0c0b21000 r-xp 00021000 08:01 1311 /app/bin/app (deleted)
+7f1c2c000000-7f1c2c021000 rw-p 00000000 00:00 0
+7f1c2d000000-7f1c2d195000 r-xp 00028000 08:01 2201 /usr/lib/libc.so.6
+7f1c2d200000-7f1c2d201000 r-xp 00000000 08:01 2202 /tmp/my lib.so
+7f1c2d300000-7f1c2d301000 r--p 00000000 08:01 2203 /usr/share/locale/data
+7f1c2d400000-7f1c2d401000 r-xp 00000000 00:01 1025 /memfd:payload (deleted)
+7ffd1e5f6000-7ffd1e5f8000 r-xp 00000000 00:00 0 [vdso]
+`
+	require.NoError(t, os.WriteFile(filepath.Join(procDir, "10", "maps"), []byte(maps), 0o644))
+	viper.Set("proc-dir", procDir)
+	defer viper.Set("proc-dir", "")
+
+	exes, err := Executables(10)
+	require.NoError(t, err)
+	assert.Equal(t, []Executable{
+		{Path: "/app/bin/app", Deleted: true},
+		{Path: "/usr/lib/libc.so.6"},
+		{Path: "/tmp/my lib.so"},
+		{Path: "/memfd:payload", Deleted: true},
+	}, exes)
+
+	_, err = Executables(11)
+	assert.Error(t, err)
+}
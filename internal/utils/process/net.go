@@ -0,0 +1,139 @@
+package process
+
+import (
+	"bufio"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Socket states from include/net/tcp_states.h
+const (
+	tcpListen = "0A"
+	tcpClose  = "07"
+)
+
+// Listener is a listening TCP or a bound unconnected UDP socket.
+type Listener struct {
+	Proto string // tcp, tcp6, udp or udp6
+	IP    net.IP
+	Port  int
+	Inode string
+}
+
+// String returns the listener as "proto/ip:port".
+func (l Listener) String() string {
+	return l.Proto + "/" + net.JoinHostPort(l.IP.String(), strconv.Itoa(l.Port))
+}
+
+// Listeners returns the listening sockets of the network namespace of the
+// @pid process, read from /proc/<pid>/net/{tcp,tcp6,udp,udp6}.
+func Listeners(pid int) ([]Listener, error) {
+	var res []Listener
+	for _, proto := range []string{"tcp", "tcp6", "udp", "udp6"} {
+		l, err := readListeners(fmt.Sprintf("%s/%d/net/%s", procDir(), pid, proto), proto)
+		if err != nil {
+			// IPv6 might be disabled
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, err
+		}
+		res = append(res, l...)
+	}
+	return res, nil
+}
+
+func readListeners(path, proto string) ([]Listener, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var res []Listener
+	s := bufio.NewScanner(f)
+	// header
+	s.Scan()
+	for s.Scan() {
+		l, ok, err := parseSocket(s.Text(), proto)
+		if err != nil {
+			return nil, fmt.Errorf("failed parse %s: %w", path, err)
+		}
+		if ok {
+			res = append(res, l)
+		}
+	}
+	return res, s.Err()
+}
+
+// parseSocket parses a line of /proc/net/{tcp,udp}[6]:
+// "sl local_address rem_address st tx_queue:rx_queue tr:tm->when retrnsmt uid timeout inode ..."
+// Returns ok=false for the sockets which are not listening.
+func parseSocket(line, proto string) (Listener, bool, error) {
+	fields := strings.Fields(line)
+	if len(fields) < 10 {
+		return Listener{}, false, fmt.Errorf("incorrect socket line %q", line)
+	}
+	state := fields[3]
+	listening := state == tcpListen
+	if strings.HasPrefix(proto, "udp") {
+		// bound unconnected sockets receive datagrams from anyone
+		listening = state == tcpClose && strings.HasSuffix(fields[2], ":0000")
+	}
+	if !listening {
+		return Listener{}, false, nil
+	}
+
+	ip, port, err := parseAddr(fields[1])
+	if err != nil {
+		return Listener{}, false, err
+	}
+	return Listener{Proto: proto, IP: ip, Port: port, Inode: fields[9]}, true, nil
+}
+
+// parseAddr parses the "IP:PORT" address in hex, the IP is stored as 32-bit
+// words in the host byte order.
+func parseAddr(s string) (net.IP, int, error) {
+	ipHex, portHex, ok := strings.Cut(s, ":")
+	if !ok {
+		return nil, 0, fmt.Errorf("incorrect address %q", s)
+	}
+	port, err := strconv.ParseUint(portHex, 16, 16)
+	if err != nil {
+		return nil, 0, fmt.Errorf("incorrect port %q: %w", portHex, err)
+	}
+	b, err := hex.DecodeString(ipHex)
+	if err != nil || (len(b) != net.IPv4len && len(b) != net.IPv6len) {
+		return nil, 0, fmt.Errorf("incorrect ip %q", ipHex)
+	}
+	// little endian words, as on all the architectures kubernetes runs on
+	for i := 0; i < len(b); i += 4 {
+		b[i], b[i+1], b[i+2], b[i+3] = b[i+3], b[i+2], b[i+1], b[i]
+	}
+	return net.IP(b), int(port), nil
+}
+
+// SocketInodes returns the inodes of the sockets opened by the @pid process.
+func SocketInodes(pid int) (map[string]struct{}, error) {
+	dir := fmt.Sprintf("%s/%d/fd", procDir(), pid)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	res := make(map[string]struct{})
+	for _, e := range entries {
+		link, err := os.Readlink(dir + "/" + e.Name())
+		if err != nil {
+			continue
+		}
+		// socket:[12345]
+		if strings.HasPrefix(link, "socket:[") {
+			res[strings.TrimSuffix(strings.TrimPrefix(link, "socket:["), "]")] = struct{}{}
+		}
+	}
+	return res, nil
+}
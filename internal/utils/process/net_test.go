@@ -0,0 +1,50 @@
+package process
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListeners(t *testing.T) {
+	procDir := fakeProcDir(t, []fakeProc{{pid: 10, comm: "nginx", exe: "/usr/sbin/nginx"}})
+	netDir := filepath.Join(procDir, "10", "net")
+	require.NoError(t, os.Mkdir(netDir, 0o755))
+	files := map[string]string{
+		"tcp": `  sl  local_address rem_address   st tx_queue rx_queue tr tm->when retrnsmt   uid  timeout inode
+   0: 00000000:0050 00000000:0000 0A 00000000:00000000 00:00000000 00000000     0        0 1001 1 0000000000000000 100 0 0 10 0
+   1: 0100007F:115C 00000000:0000 0A 00000000:00000000 00:00000000 00000000     0        0 1002 1 0000000000000000 100 0 0 10 0
+   2: 0100007F:0050 0100007F:C350 01 00000000:00000000 00:00000000 00000000     0        0 1003 1 0000000000000000 20 4 30 10 -1
+`,
+		"tcp6": `  sl  local_address                         remote_address                        st tx_queue rx_queue tr tm->when retrnsmt   uid  timeout inode
+   0: 00000000000000000000000000000000:0050 00000000000000000000000000000000:0000 0A 00000000:00000000 00:00000000 00000000     0        0 1004 1 0000000000000000 100 0 0 10 0
+`,
+		"udp": `   sl  local_address rem_address   st tx_queue rx_queue tr tm->when retrnsmt   uid  timeout inode ref pointer drops
+  100: 00000000:0035 00000000:0000 07 00000000:00000000 00:00000000 00000000     0        0 1005 2 0000000000000000 0
+  101: 0100007F:D431 0100007F:0035 01 00000000:00000000 00:00000000 00000000     0        0 1006 2 0000000000000000 0
+`,
+	}
+	for name, content := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(netDir, name), []byte(content), 0o644))
+	}
+	viper.Set("proc-dir", procDir)
+	defer viper.Set("proc-dir", "")
+
+	listeners, err := Listeners(10)
+	require.NoError(t, err)
+	var got []string
+	for _, l := range listeners {
+		got = append(got, l.String()+" "+l.Inode)
+	}
+	// missing udp6 is skipped
+	assert.Equal(t, []string{
+		"tcp/0.0.0.0:80 1001",
+		"tcp/127.0.0.1:4444 1002",
+		"tcp6/[::]:80 1004",
+		"udp/0.0.0.0:53 1005",
+	}, got)
+}
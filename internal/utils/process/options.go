@@ -0,0 +1,21 @@
+package process
+
+import (
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// Options returns the per process values of the @key option given as repeated
+// process=value items. Unlike the StringToString options the items are not
+// split on commas, so the values may contain regular expressions like a{1,3}.
+func Options(key string) map[string]string {
+	res := make(map[string]string)
+	for _, item := range viper.GetStringSlice(key) {
+		proc, value, ok := strings.Cut(item, "=")
+		if ok && proc != "" {
+			res[proc] = value
+		}
+	}
+	return res
+}
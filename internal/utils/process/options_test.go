@@ -0,0 +1,29 @@
+package process
+
+import (
+	"testing"
+
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOptions(t *testing.T) {
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	fs.StringArray("test-process-allowlist", nil, "")
+	assert.NoError(t, fs.Parse([]string{
+		`--test-process-allowlist=nginx=/usr/sbin/nginx;/bin/sh:^sh -c "sleep [0-9]{1,3}"$`,
+		"--test-process-allowlist=app=/app/[a,b]*",
+		"--test-process-allowlist=broken",
+	}))
+	v := viper.New()
+	assert.NoError(t, v.BindPFlags(fs))
+	viper.Set("test-process-allowlist", v.GetStringSlice("test-process-allowlist"))
+	defer viper.Set("test-process-allowlist", nil)
+
+	assert.Equal(t, map[string]string{
+		"nginx": `/usr/sbin/nginx;/bin/sh:^sh -c "sleep [0-9]{1,3}"$`,
+		"app":   "/app/[a,b]*",
+	}, Options("test-process-allowlist"))
+	assert.Empty(t, Options("test-missing"))
+}
@@ -2,24 +2,75 @@ package process
 
 import (
 	"errors"
-	"os/exec"
-	"strconv"
-	"strings"
+	"fmt"
+
+	"github.com/spf13/viper"
 )
 
 // ErrProcessNotFound - error process not found
 var ErrProcessNotFound = errors.New("process not found")
 
-// GetPID returns process PID by name
+const defaultProcDir = "/proc"
+
+// GetPID returns the lowest PID of the processes matching the @procName
 func GetPID(procName string) (int, error) {
-	cmdOut, err := exec.Command("pidof", procName).Output()
+	pids, err := GetPIDs(procName)
+	if err != nil {
+		return 0, err
+	}
+	return pids[0], nil
+}
+
+// GetPIDs returns PIDs of all the processes matching the @procName, the match
+// criteria are taken from the "process-match" option, by default the name is
+// compared like pidof does.
+func GetPIDs(procName string) ([]int, error) {
+	m, err := ProcessMatcher(procName)
+	if err != nil {
+		return nil, err
+	}
+	return FindPIDs(procDir(), m)
+}
+
+// Roots returns the root file system paths "<proc-dir>/<pid>/root/" of all the
+// @procName instances. The instances sharing the mount namespace have the same
+// file system view, only the first of them is returned.
+func Roots(procName string) ([]string, error) {
+	pids, err := GetPIDs(procName)
 	if err != nil {
-		return 0, ErrProcessNotFound
+		return nil, err
+	}
+	namespaces := make(map[string]struct{})
+	roots := make([]string, 0, len(pids))
+	for _, pid := range pids {
+		ns, err := MountNamespace(pid)
+		if err == nil {
+			if _, ok := namespaces[ns]; ok {
+				continue
+			}
+			namespaces[ns] = struct{}{}
+		}
+		roots = append(roots, Root(pid))
+	}
+	return roots, nil
+}
+
+func procDir() string {
+	if dir := viper.GetString("proc-dir"); dir != "" {
+		return dir
 	}
-	ss := strings.Split(string(cmdOut), " ")
-	pid, err := strconv.Atoi(strings.TrimSpace(ss[0]))
-	if err != nil || pid == 0 {
-		return 0, ErrProcessNotFound
+	return defaultProcDir
+}
+
+// ProcessMatcher returns the matcher configured for the @procName process.
+func ProcessMatcher(procName string) (Matcher, error) {
+	spec, ok := Options("process-match")[procName]
+	if !ok {
+		return NameMatcher(procName), nil
+	}
+	m, err := ParseMatcher(spec)
+	if err != nil {
+		return Matcher{}, fmt.Errorf("process %s: %w", procName, err)
 	}
-	return pid, nil
+	return m, nil
 }
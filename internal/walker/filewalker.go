@@ -6,30 +6,43 @@ import (
 	"path/filepath"
 
 	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/ScienceSoft-Inc/integrity-sum/pkg/tracing"
 )
 
+// ChanWalkDir walks the @dirPaths and sends the paths of regular files,
+// directories, symlinks and special files into the returned channel.
 func ChanWalkDir(ctx context.Context, dirPaths []string, log *logrus.Logger) <-chan string {
 	fileNamesChan := make(chan string)
 	go func() {
 		defer close(fileNamesChan)
+		_, span := tracing.Start(ctx, "walker.WalkDir", attribute.StringSlice("paths", dirPaths))
+		defer span.End()
+		entries := 0
+		defer func() { span.SetAttributes(attribute.Int("entries", entries)) }()
+
 		for _, dirPath := range dirPaths {
-			if err := filepath.WalkDir(dirPath, func(filePath string, d fs.DirEntry, err error) error {
+			if err := filepath.WalkDir(filepath.Clean(dirPath), func(filePath string, d fs.DirEntry, err error) error {
 				if err != nil {
 					return err
 				}
 
-				if !d.Type().IsRegular() {
+				// sockets are created at runtime and not tracked
+				if d.Type()&fs.ModeSocket != 0 {
 					return nil
 				}
 
 				select {
 				case fileNamesChan <- filePath:
+					entries++
 					return nil
 				case <-ctx.Done():
 					return ctx.Err()
 				}
 			}); err != nil {
-				log.WithError(err).Error("file walker")
+				span.RecordError(err)
+				log.WithContext(ctx).WithError(err).Error("file walker")
 			}
 		}
 	}()
@@ -0,0 +1,11 @@
+// Package watcher provides the real-time change detection of the monitored
+// file system.
+package watcher
+
+// Event describes a change of the watched file system.
+type Event struct {
+	// Path of the changed file or directory
+	Path string
+	// Overflow is set if some events were lost, the full scan is required
+	Overflow bool
+}
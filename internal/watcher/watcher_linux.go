@@ -0,0 +1,170 @@
+package watcher
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"unsafe"
+
+	"github.com/sirupsen/logrus"
+	"golang.org/x/sys/unix"
+)
+
+const watchMask = unix.IN_CREATE | unix.IN_MODIFY | unix.IN_CLOSE_WRITE | unix.IN_ATTRIB |
+	unix.IN_DELETE | unix.IN_MOVED_FROM | unix.IN_MOVED_TO | unix.IN_DELETE_SELF | unix.IN_MOVE_SELF
+
+// errStopped - watching has been stopped while walking a new directory
+var errStopped = errors.New("watching stopped")
+
+type inotify struct {
+	log  *logrus.Logger
+	file *os.File
+	fd   int
+	dirs map[int]string // watch descriptor -> directory
+	eC   chan Event
+	done <-chan struct{}
+}
+
+// Watch starts watching the @dirPaths recursively with inotify and returns
+// the channel of changes. The channel is closed when the context is canceled
+// or all watched directories are gone (e.g. the monitored process exited).
+func Watch(ctx context.Context, log *logrus.Logger, dirPaths []string) (<-chan Event, error) {
+	fd, err := unix.InotifyInit1(unix.IN_CLOEXEC | unix.IN_NONBLOCK)
+	if err != nil {
+		return nil, fmt.Errorf("failed init inotify: %w", err)
+	}
+	w := &inotify{
+		log:  log,
+		file: os.NewFile(uintptr(fd), "inotify"),
+		fd:   fd,
+		dirs: make(map[int]string),
+		eC:   make(chan Event),
+		done: ctx.Done(),
+	}
+
+	for _, p := range dirPaths {
+		if err = w.addRecursive(filepath.Clean(p), false); err != nil {
+			w.file.Close()
+			return nil, err
+		}
+	}
+
+	go func() {
+		<-ctx.Done()
+		// unblocks the read
+		w.file.Close()
+	}()
+	go w.run()
+	return w.eC, nil
+}
+
+// addRecursive adds watches for the @root directory and all its
+// subdirectories. If @notify is set, an event is sent for every found file,
+// since they might be created before the watch was added.
+func (w *inotify) addRecursive(root string, notify bool) error {
+	return filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if notify && errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if notify && path != root && !w.send(Event{Path: path}) {
+			return errStopped
+		}
+		if !d.IsDir() {
+			return nil
+		}
+
+		wd, err := unix.InotifyAddWatch(w.fd, path, watchMask|unix.IN_ONLYDIR|unix.IN_DONT_FOLLOW)
+		if err != nil {
+			if errors.Is(err, unix.ENOSPC) {
+				return fmt.Errorf("inotify watches limit reached, increase fs.inotify.max_user_watches: %w", err)
+			}
+			return fmt.Errorf("failed add watch for %s: %w", path, err)
+		}
+		w.dirs[wd] = path
+		return nil
+	})
+}
+
+func (w *inotify) send(e Event) bool {
+	select {
+	case w.eC <- e:
+		return true
+	case <-w.done:
+		return false
+	}
+}
+
+func (w *inotify) run() {
+	defer func() {
+		close(w.eC)
+		w.file.Close()
+	}()
+
+	buf := make([]byte, 64*(unix.SizeofInotifyEvent+unix.NAME_MAX+1))
+	for {
+		n, err := w.file.Read(buf)
+		if err != nil {
+			select {
+			case <-w.done:
+			default:
+				w.log.WithError(err).Error("inotify read")
+			}
+			return
+		}
+
+		for offset := 0; offset+unix.SizeofInotifyEvent <= n; {
+			raw := (*unix.InotifyEvent)(unsafe.Pointer(&buf[offset]))
+			nameStart := offset + unix.SizeofInotifyEvent
+			name := string(bytes.TrimRight(buf[nameStart:nameStart+int(raw.Len)], "\x00"))
+			offset = nameStart + int(raw.Len)
+
+			if !w.handle(int(raw.Wd), raw.Mask, name) {
+				return
+			}
+		}
+	}
+}
+
+// handle processes a single inotify event, returns false if watching should
+// be stopped.
+func (w *inotify) handle(wd int, mask uint32, name string) bool {
+	if mask&unix.IN_Q_OVERFLOW != 0 {
+		w.log.Warn("inotify queue overflow, events are lost")
+		return w.send(Event{Overflow: true})
+	}
+
+	dir, ok := w.dirs[wd]
+	if !ok {
+		return true
+	}
+	if mask&unix.IN_IGNORED != 0 {
+		delete(w.dirs, wd)
+		if len(w.dirs) == 0 {
+			w.log.Info("all watched directories are gone")
+			return false
+		}
+		return true
+	}
+
+	path := dir
+	if name != "" {
+		path = filepath.Join(dir, name)
+	}
+	if mask&unix.IN_ISDIR != 0 && mask&(unix.IN_CREATE|unix.IN_MOVED_TO) != 0 {
+		if err := w.addRecursive(path, true); err != nil {
+			if errors.Is(err, errStopped) {
+				return false
+			}
+			w.log.WithError(err).WithField("path", path).Error("failed watch new directory")
+			return w.send(Event{Overflow: true})
+		}
+	}
+	return w.send(Event{Path: path})
+}
@@ -0,0 +1,57 @@
+package watcher
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWatch(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	root := t.TempDir()
+	eventC, err := Watch(ctx, logrus.New(), []string{root})
+	assert.NoError(t, err)
+
+	// waits for an event for the @path
+	waitFor := func(path string) {
+		t.Helper()
+		timeout := time.After(5 * time.Second)
+		for {
+			select {
+			case e, ok := <-eventC:
+				assert.True(t, ok, "events channel closed")
+				if e.Path == path {
+					return
+				}
+			case <-timeout:
+				t.Fatalf("no event for %s", path)
+			}
+		}
+	}
+
+	file := filepath.Join(root, "file")
+	assert.NoError(t, os.WriteFile(file, []byte("data"), 0644))
+	waitFor(file)
+
+	// files of the new directory are reported and watched
+	subDir := filepath.Join(root, "dir")
+	assert.NoError(t, os.Mkdir(subDir, 0755))
+	waitFor(subDir)
+	subFile := filepath.Join(subDir, "file")
+	assert.NoError(t, os.WriteFile(subFile, []byte("data"), 0644))
+	waitFor(subFile)
+
+	assert.NoError(t, os.Remove(file))
+	waitFor(file)
+
+	cancel()
+	for range eventC {
+	}
+}
@@ -0,0 +1,15 @@
+//go:build !linux
+
+package watcher
+
+import (
+	"context"
+	"errors"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Watch is supported on linux only.
+func Watch(_ context.Context, _ *logrus.Logger, _ []string) (<-chan Event, error) {
+	return nil, errors.New("file system watching is supported on linux only")
+}
@@ -0,0 +1,29 @@
+package worker
+
+import (
+	"golang.org/x/sys/unix"
+)
+
+const (
+	lowestNice = 19
+
+	ioprioWhoProcess = 1
+	ioprioClassBE    = 2
+	ioprioClassShift = 13
+	ioprioLowestBE   = 7
+)
+
+// lowerThreadPriority sets the lowest CPU and best-effort I/O priority for the
+// calling OS thread, on linux both are per-thread.
+func lowerThreadPriority() error {
+	tid := unix.Gettid()
+	if err := unix.Setpriority(unix.PRIO_PROCESS, tid, lowestNice); err != nil {
+		return err
+	}
+	_, _, errno := unix.Syscall(unix.SYS_IOPRIO_SET, ioprioWhoProcess, uintptr(tid),
+		ioprioClassBE<<ioprioClassShift|ioprioLowestBE)
+	if errno != 0 {
+		return errno
+	}
+	return nil
+}
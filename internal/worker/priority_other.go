@@ -0,0 +1,10 @@
+//go:build !linux
+
+package worker
+
+import "errors"
+
+// lowerThreadPriority is supported on linux only.
+func lowerThreadPriority() error {
+	return errors.New("lowering thread priority is supported on linux only")
+}
@@ -0,0 +1,137 @@
+package worker
+
+import (
+	"io/fs"
+	"sync"
+)
+
+// statKey identifies the file content by its stat data, the content is
+// considered unchanged while the key is the same.
+type statKey struct {
+	dev   uint64
+	ino   uint64
+	size  int64
+	mtime int64
+	ctime int64
+}
+
+type statEntry struct {
+	key  statKey
+	hash string
+	gen  uint64
+}
+
+type xattrsEntry struct {
+	key    statKey
+	xattrs map[string]string
+	gen    uint64
+}
+
+// StatCache keeps the calculated hashes and the read extended attributes of
+// the files keyed by the path, inode, size, mtime and ctime, so the files with
+// unchanged stat data are not rehashed on every scan. A change of the extended
+// attributes updates the ctime.
+type StatCache struct {
+	mu      sync.Mutex
+	entries map[string]*statEntry
+	xattrs  map[string]*xattrsEntry
+	gen     uint64
+	full    bool
+}
+
+func NewStatCache() *StatCache {
+	return &StatCache{entries: make(map[string]*statEntry), xattrs: make(map[string]*xattrsEntry)}
+}
+
+// Begin starts a next scan cycle. Every @fullEvery cycle the cached hashes
+// are not used and all the files are rehashed, since the timestamps might be
+// forged. Zero @fullEvery disables the full rehash. Returns true for the full
+// rehash cycle.
+func (c *StatCache) Begin(fullEvery int) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.gen++
+	c.full = fullEvery > 0 && c.gen%uint64(fullEvery) == 0
+	return c.full
+}
+
+// Get returns the cached hash of the @path file if its stat data @info has
+// not changed since the hash was calculated.
+func (c *StatCache) Get(path string, info fs.FileInfo) (string, bool) {
+	key, ok := statKeyOf(info)
+	if !ok {
+		return "", false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[path]
+	if !ok || c.full || e.key != key {
+		return "", false
+	}
+	e.gen = c.gen
+	return e.hash, true
+}
+
+// Put stores the @hash of the @path file calculated for the stat data @info.
+func (c *StatCache) Put(path string, info fs.FileInfo, hash string) {
+	key, ok := statKeyOf(info)
+	if !ok {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[path] = &statEntry{key: key, hash: hash, gen: c.gen}
+}
+
+// Xattrs returns the cached extended attributes of the @path file if its stat
+// data @info has not changed since they were read. The returned map must not
+// be modified.
+func (c *StatCache) Xattrs(path string, info fs.FileInfo) (map[string]string, bool) {
+	key, ok := statKeyOf(info)
+	if !ok {
+		return nil, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.xattrs[path]
+	if !ok || c.full || e.key != key {
+		return nil, false
+	}
+	e.gen = c.gen
+	return e.xattrs, true
+}
+
+// PutXattrs stores the @xattrs of the @path file read for the stat data @info.
+func (c *StatCache) PutXattrs(path string, info fs.FileInfo, xattrs map[string]string) {
+	key, ok := statKeyOf(info)
+	if !ok {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.xattrs[path] = &xattrsEntry{key: key, xattrs: xattrs, gen: c.gen}
+}
+
+// Prune removes the entries of the files not seen in the current cycle, it
+// should be called once the whole file tree has been scanned.
+func (c *StatCache) Prune() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for p, e := range c.entries {
+		if e.gen != c.gen {
+			delete(c.entries, p)
+		}
+	}
+	for p, e := range c.xattrs {
+		if e.gen != c.gen {
+			delete(c.xattrs, p)
+		}
+	}
+}
+
+// Len returns the number of the cached hashes.
+func (c *StatCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.entries)
+}
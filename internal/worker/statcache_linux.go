@@ -0,0 +1,20 @@
+package worker
+
+import (
+	"io/fs"
+	"syscall"
+)
+
+func statKeyOf(info fs.FileInfo) (statKey, bool) {
+	st, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return statKey{}, false
+	}
+	return statKey{
+		dev:   uint64(st.Dev), //nolint:unconvert // Dev type differs between platforms
+		ino:   st.Ino,
+		size:  st.Size,
+		mtime: st.Mtim.Nano(),
+		ctime: st.Ctim.Nano(),
+	}, true
+}
@@ -0,0 +1,85 @@
+package worker
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStatCache(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "file")
+	require.NoError(t, os.WriteFile(path, []byte("data"), 0o644))
+	stat := func() os.FileInfo {
+		info, err := os.Lstat(path)
+		require.NoError(t, err)
+		return info
+	}
+
+	c := NewStatCache()
+	assert.False(t, c.Begin(3))
+	_, ok := c.Get(path, stat())
+	assert.False(t, ok)
+	c.Put(path, stat(), "hash")
+
+	assert.False(t, c.Begin(3))
+	hash, ok := c.Get(path, stat())
+	assert.True(t, ok)
+	assert.Equal(t, "hash", hash)
+
+	// forged mtime still changes ctime
+	time.Sleep(10 * time.Millisecond)
+	mtime := stat().ModTime()
+	require.NoError(t, os.WriteFile(path, []byte("evil"), 0o644))
+	require.NoError(t, os.Chtimes(path, mtime, mtime))
+	_, ok = c.Get(path, stat())
+	assert.False(t, ok)
+	c.Put(path, stat(), "evil")
+
+	assert.True(t, c.Begin(3), "every 3rd cycle is a full rehash")
+	_, ok = c.Get(path, stat())
+	assert.False(t, ok)
+
+	c.Begin(3)
+	c.Prune()
+	assert.Equal(t, 0, c.Len())
+}
+
+func TestStatCacheXattrs(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "file")
+	require.NoError(t, os.WriteFile(path, []byte("data"), 0o644))
+	stat := func() os.FileInfo {
+		info, err := os.Lstat(path)
+		require.NoError(t, err)
+		return info
+	}
+
+	c := NewStatCache()
+	c.Begin(2)
+	_, ok := c.Xattrs(path, stat())
+	assert.False(t, ok)
+	xattrs := map[string]string{"security.capability": "AQAAAgAgAAAAAAAAAAAAAAAAAAA="}
+	c.PutXattrs(path, stat(), xattrs)
+	cached, ok := c.Xattrs(path, stat())
+	assert.True(t, ok)
+	assert.Equal(t, xattrs, cached)
+
+	// a change of the attributes changes ctime
+	time.Sleep(10 * time.Millisecond)
+	require.NoError(t, os.Chmod(path, 0o600))
+	_, ok = c.Xattrs(path, stat())
+	assert.False(t, ok)
+	c.PutXattrs(path, stat(), nil)
+
+	assert.True(t, c.Begin(2), "the attributes are read again on the full rehash")
+	_, ok = c.Xattrs(path, stat())
+	assert.False(t, ok)
+
+	c.Begin(2)
+	c.Prune()
+	_, ok = c.Xattrs(path, stat())
+	assert.False(t, ok)
+}
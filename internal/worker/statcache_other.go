@@ -0,0 +1,11 @@
+//go:build !linux
+
+package worker
+
+import "io/fs"
+
+// statKeyOf is supported on linux only, files are always rehashed on other
+// platforms.
+func statKeyOf(_ fs.FileInfo) (statKey, bool) {
+	return statKey{}, false
+}
@@ -0,0 +1,59 @@
+package worker
+
+import (
+	"context"
+
+	"golang.org/x/time/rate"
+)
+
+// Throttle limits the rate of the files processed and the bytes read by the
+// workers, a single Throttle is shared by all workers to limit their total
+// rate.
+type Throttle struct {
+	files *rate.Limiter
+	bytes *rate.Limiter
+}
+
+// NewThrottle creates the throttle for the @bytesPerSec and @filesPerSec
+// rates, zero value means no limit. Returns nil if both rates are unlimited.
+func NewThrottle(bytesPerSec int64, filesPerSec int) *Throttle {
+	if bytesPerSec <= 0 && filesPerSec <= 0 {
+		return nil
+	}
+	t := &Throttle{}
+	if filesPerSec > 0 {
+		t.files = rate.NewLimiter(rate.Limit(filesPerSec), 1)
+	}
+	if bytesPerSec > 0 {
+		t.bytes = rate.NewLimiter(rate.Limit(bytesPerSec), int(bytesPerSec))
+	}
+	return t
+}
+
+// WaitFile blocks until the next file is allowed to be processed.
+func (t *Throttle) WaitFile(ctx context.Context) error {
+	if t == nil || t.files == nil {
+		return nil
+	}
+	return t.files.Wait(ctx)
+}
+
+// WaitBytes blocks until @n bytes are allowed to be read. Large amounts are
+// waited for in bursts, so the reading is spread over time.
+func (t *Throttle) WaitBytes(ctx context.Context, n int64) error {
+	if t == nil || t.bytes == nil {
+		return nil
+	}
+	burst := int64(t.bytes.Burst())
+	for n > 0 {
+		chunk := n
+		if chunk > burst {
+			chunk = burst
+		}
+		if err := t.bytes.WaitN(ctx, int(chunk)); err != nil {
+			return err
+		}
+		n -= chunk
+	}
+	return nil
+}
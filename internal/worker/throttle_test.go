@@ -0,0 +1,27 @@
+package worker
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestThrottle(t *testing.T) {
+	assert.Nil(t, NewThrottle(0, 0))
+	// nil throttle does not limit
+	var unlimited *Throttle
+	assert.NoError(t, unlimited.WaitFile(context.Background()))
+	assert.NoError(t, unlimited.WaitBytes(context.Background(), 1<<30))
+
+	th := NewThrottle(1000, 100)
+	start := time.Now()
+	// the first burst is free, the rest takes ~0.5s
+	assert.NoError(t, th.WaitBytes(context.Background(), 1500))
+	assert.GreaterOrEqual(t, time.Since(start), 400*time.Millisecond)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.Error(t, th.WaitBytes(ctx, 5000))
+}
@@ -2,16 +2,28 @@ package worker
 
 import (
 	"context"
+	"io/fs"
+	"os"
+	"runtime"
 	"sync"
 
 	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/attribute"
 
+	"github.com/ScienceSoft-Inc/integrity-sum/internal/data"
 	"github.com/ScienceSoft-Inc/integrity-sum/pkg/hasher"
+	"github.com/ScienceSoft-Inc/integrity-sum/pkg/metrics"
+	"github.com/ScienceSoft-Inc/integrity-sum/pkg/tracing"
 )
 
 type FileHash struct {
-	Path string
-	Hash string
+	Path   string
+	Hash   string
+	Meta   *data.FileMeta
+	Type   string // empty for regular files
+	Target string // symlink target or device "major:minor"
+	// Oversize is the policy applied to the file exceeding the size limit
+	Oversize string
 }
 
 type HashWorker func(ind int, fileNameC <-chan string, hashC chan<- FileHash)
@@ -34,8 +46,90 @@ func WorkersPool(countWorkers int, fileNameC <-chan string, w HashWorker) <-chan
 	return hashC
 }
 
-func NewWorker(ctx context.Context, algName string, log *logrus.Logger) HashWorker {
+// Policies for the files larger than the size limit
+const (
+	OversizeSkip      = "skip"      // the file content is not verified
+	OversizeHeadTail  = "head-tail" // only the head and the tail are hashed
+	OversizeViolation = "violation" // the file is reported as a violation
+)
+
+// OversizePolicies lists the available policies for the oversized files.
+var OversizePolicies = []string{OversizeSkip, OversizeHeadTail, OversizeViolation}
+
+type options struct {
+	cache          *StatCache
+	maxSize        int64
+	oversizePolicy string
+	throttle       *Throttle
+	lowPriority    bool
+	wantXattrs     func(path string) bool
+}
+
+type Option func(*options)
+
+// WithStatCache makes the worker take the hashes of the regular files from
+// the @cache if their stat data has not changed.
+func WithStatCache(cache *StatCache) Option {
+	return func(o *options) {
+		o.cache = cache
+	}
+}
+
+// WithXattrsFilter makes the worker read the extended attributes only of the
+// files @want reports, e.g. of the files whose snapshot records have metadata.
+// The attributes of all the files are read by default.
+func WithXattrsFilter(want func(path string) bool) Option {
+	return func(o *options) {
+		o.wantXattrs = want
+	}
+}
+
+// WithSizeLimit applies the @policy to the files larger than @maxSize bytes.
+// Zero @maxSize disables the limit.
+func WithSizeLimit(maxSize int64, policy string) Option {
+	return func(o *options) {
+		o.maxSize = maxSize
+		o.oversizePolicy = policy
+	}
+}
+
+// WithThrottle limits the rate of the worker with the @throttle shared by all
+// workers. The @throttle may be nil, then the rate is not limited.
+func WithThrottle(throttle *Throttle) Option {
+	return func(o *options) {
+		o.throttle = throttle
+	}
+}
+
+// WithLowPriority runs the worker in a dedicated OS thread with the lowest CPU
+// and I/O priority.
+func WithLowPriority() Option {
+	return func(o *options) {
+		o.lowPriority = true
+	}
+}
+
+func NewWorker(ctx context.Context, algName string, log *logrus.Logger, opts ...Option) HashWorker {
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
 	return func(ind int, fileNameC <-chan string, hashC chan<- FileHash) {
+		if o.lowPriority {
+			// the thread is never unlocked, so it is terminated along with the
+			// worker and the lowered priority does not affect other goroutines
+			runtime.LockOSThread()
+			if err := lowerThreadPriority(); err != nil {
+				log.WithError(err).Warn("failed lower worker priority")
+			}
+		}
+
+		// the files hashed by the worker during the scan form its batch
+		_, span := tracing.Start(ctx, "worker.HashBatch", attribute.Int("worker", ind))
+		defer span.End()
+		var files, failed int
+		defer func() { span.SetAttributes(attribute.Int("files", files), attribute.Int("failed", failed)) }()
+
 		h := hasher.NewFileHasher(algName, log)
 		for v := range fileNameC {
 			select {
@@ -43,16 +137,113 @@ func NewWorker(ctx context.Context, algName string, log *logrus.Logger) HashWork
 				return
 			default:
 			}
+			if err := o.throttle.WaitFile(ctx); err != nil {
+				return
+			}
 
-			hash, err := h.HashFile(v)
+			info, err := os.Lstat(v)
+			if err != nil {
+				log.WithError(err).WithField("file", v).Error("stat file")
+				continue
+			}
+			typ, target, ok, err := data.FileType(v, info)
 			if err != nil {
-				log.WithError(err).WithField("file", v).Error("calculate hash")
+				log.WithError(err).WithField("file", v).Error("read file type")
 				continue
 			}
+			if !ok {
+				continue
+			}
+
+			hash := data.NoHash
+			oversize := ""
+			if typ == "" {
+				if o.maxSize > 0 && info.Size() > o.maxSize {
+					oversize = o.oversizePolicy
+					log.WithFields(logrus.Fields{"file": v, "size": info.Size(), "policy": oversize}).
+						Debug("file exceeds the size limit")
+				}
+				hash, err = o.hashFile(ctx, h, v, info, oversize)
+				if err != nil {
+					log.WithError(err).WithField("file", v).Error("calculate hash")
+					failed++
+					continue
+				}
+			}
+			meta := data.FileMetaOf(info)
+			if o.wantXattrs == nil || o.wantXattrs(v) {
+				if meta.Xattrs, err = o.xattrs(v, info); err != nil {
+					log.WithError(err).WithField("file", v).Error("read file metadata")
+					meta = nil
+				}
+			}
+			files++
 			hashC <- FileHash{
-				Path: v,
-				Hash: hash,
+				Path:     v,
+				Hash:     hash,
+				Meta:     meta,
+				Type:     typ,
+				Target:   target,
+				Oversize: oversize,
 			}
 		}
 	}
 }
+
+// hashFile calculates the hash of a regular file according to the @oversize
+// policy applied to it, the content of skipped and violating files is not read.
+func (o *options) hashFile(ctx context.Context, h *hasher.Hasher, path string, info fs.FileInfo, oversize string) (string, error) {
+	switch oversize {
+	case OversizeSkip, OversizeViolation:
+		return data.NoHash, nil
+	}
+
+	if o.cache != nil {
+		if hash, ok := o.cache.Get(path, info); ok {
+			metrics.StatCacheHits.Inc()
+			return hash, nil
+		}
+	}
+	size := info.Size()
+	if oversize == OversizeHeadTail {
+		size = o.maxSize
+	}
+	if err := o.throttle.WaitBytes(ctx, size); err != nil {
+		return "", err
+	}
+
+	var hash string
+	var err error
+	if oversize == OversizeHeadTail {
+		hash, err = h.HashFileHeadTail(path, o.maxSize)
+	} else {
+		hash, err = h.HashFile(path)
+	}
+	if err != nil {
+		return "", err
+	}
+	metrics.FilesHashed.Inc()
+	metrics.BytesHashed.Add(float64(size))
+	if o.cache != nil {
+		o.cache.Put(path, info, hash)
+	}
+	return hash, nil
+}
+
+// xattrs returns the security extended attributes of the file, they are taken
+// from the stat cache if its stat data has not changed.
+func (o *options) xattrs(path string, info fs.FileInfo) (map[string]string, error) {
+	if o.cache != nil {
+		if xattrs, ok := o.cache.Xattrs(path, info); ok {
+			return xattrs, nil
+		}
+	}
+	xattrs, err := data.SecurityXattrs(path)
+	if err != nil {
+		return nil, err
+	}
+	if o.cache != nil {
+		o.cache.PutXattrs(path, info, xattrs)
+	}
+	return xattrs, nil
+}
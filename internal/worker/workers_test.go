@@ -1,10 +1,16 @@
 package worker
 
 import (
+	"context"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ScienceSoft-Inc/integrity-sum/internal/data"
 )
 
 func TestWorkersPool(t *testing.T) {
@@ -45,3 +51,64 @@ func mockWorker(ind int, fileNameC <-chan string, hashC chan<- FileHash) {
 	}
 	logrus.WithField("ind", ind).Info("worker stopped")
 }
+
+func TestWorkerSizeLimit(t *testing.T) {
+	dir := t.TempDir()
+	small := filepath.Join(dir, "small")
+	large := filepath.Join(dir, "large")
+	require.NoError(t, os.WriteFile(small, []byte("data"), 0o644))
+	require.NoError(t, os.WriteFile(large, []byte("large file data"), 0o644))
+
+	hashes := func(policy string) map[string]FileHash {
+		fileNameC := make(chan string, 2)
+		fileNameC <- small
+		fileNameC <- large
+		close(fileNameC)
+		res := make(map[string]FileHash)
+		w := NewWorker(context.Background(), "sha256", logrus.New(), WithSizeLimit(8, policy))
+		for v := range WorkersPool(1, fileNameC, w) {
+			res[filepath.Base(v.Path)] = v
+		}
+		return res
+	}
+
+	for _, policy := range OversizePolicies {
+		res := hashes(policy)
+		assert.Empty(t, res["small"].Oversize, policy)
+		assert.NotEqual(t, data.NoHash, res["small"].Hash, policy)
+		assert.Equal(t, policy, res["large"].Oversize, policy)
+		if policy == OversizeHeadTail {
+			assert.NotEqual(t, data.NoHash, res["large"].Hash, policy)
+		} else {
+			assert.Equal(t, data.NoHash, res["large"].Hash, policy)
+		}
+	}
+}
+
+func TestWorkerXattrsFilter(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "file")
+	require.NoError(t, os.WriteFile(file, []byte("data"), 0o640))
+
+	var filtered []string
+	fileNameC := make(chan string, 1)
+	fileNameC <- file
+	close(fileNameC)
+	w := NewWorker(context.Background(), "sha256", logrus.New(), WithXattrsFilter(func(p string) bool {
+		filtered = append(filtered, p)
+		return false
+	}))
+	var res []FileHash
+	for v := range WorkersPool(1, fileNameC, w) {
+		res = append(res, v)
+	}
+
+	// the metadata is taken from the stat data of the file
+	require.Len(t, res, 1)
+	require.NotNil(t, res[0].Meta)
+	assert.Equal(t, uint32(0o640), res[0].Meta.Mode)
+	assert.Equal(t, int64(4), res[0].Meta.Size)
+	assert.Equal(t, uint32(os.Getuid()), res[0].Meta.Uid)
+	assert.Nil(t, res[0].Meta.Xattrs)
+	assert.Equal(t, []string{file}, filtered)
+}
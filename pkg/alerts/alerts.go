@@ -2,9 +2,15 @@ package alerts
 
 import (
 	"context"
+	"fmt"
+	"strings"
 	"time"
 
 	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/ScienceSoft-Inc/integrity-sum/pkg/metrics"
+	"github.com/ScienceSoft-Inc/integrity-sum/pkg/tracing"
 )
 
 // default heartbeat interval 20 min
@@ -17,6 +23,13 @@ type Alert struct {
 	Reason      string
 	Path        string
 	ProcessName string
+	// Audit marks the alerts of the processes monitored in the audit mode,
+	// the response action is not performed for them
+	Audit bool
+	// Evidence references the evidence object of the violations
+	Evidence string
+	// TraceID is the ID of the trace of the scan which raised the alert
+	TraceID string
 }
 
 func New(msg, reason, path, procName string) Alert {
@@ -33,16 +46,42 @@ type Sender interface {
 	Send(alert Alert) error
 }
 
+// Named is implemented by the senders to name them in the metrics
+type Named interface {
+	Name() string
+}
+
+// senderName returns the name of the @s sender
+func senderName(s Sender) string {
+	if n, ok := s.(Named); ok {
+		return n.Name()
+	}
+	return strings.TrimPrefix(fmt.Sprintf("%T", s), "*")
+}
+
 var registry = []Sender{}
 
 func Register(s Sender) {
 	registry = append(registry, s)
 }
 
-func Send(alert Alert) error {
+// Send sends the @alert with all registered senders, the trace ID of the @ctx
+// is attached to it.
+func Send(ctx context.Context, alert Alert) error {
+	ctx, span := tracing.Start(ctx, "alerts.Send", attribute.String("alert.reason", alert.Reason))
+	defer span.End()
+	if alert.TraceID == "" {
+		alert.TraceID = tracing.TraceID(ctx)
+	}
+
 	var errs Errors
 	for _, s := range registry {
-		if err := s.Send(alert); err != nil {
+		name := senderName(s)
+		_, sendSpan := tracing.Start(ctx, "alerts.Send "+name, attribute.String("alert.sender", name))
+		err := s.Send(alert)
+		tracing.End(sendSpan, err)
+		metrics.AlertsSent.WithLabelValues(name, metrics.Result(err)).Inc()
+		if err != nil {
 			errs.collect(err)
 		}
 	}
@@ -59,7 +98,7 @@ func Heartbeat(ctx context.Context, logger *logrus.Logger, alert Alert) {
 			select {
 			case <-t.C:
 				alert.Time = time.Now()
-				if err := Send(alert); err != nil {
+				if err := Send(ctx, alert); err != nil {
 					logger.WithField("heartbeat", "send").Error(err)
 				}
 			case <-ctx.Done():
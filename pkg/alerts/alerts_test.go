@@ -0,0 +1,65 @@
+package alerts
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/ScienceSoft-Inc/integrity-sum/pkg/metrics"
+)
+
+type fakeSender struct {
+	err error
+}
+
+func (s fakeSender) Name() string { return "fake" }
+
+func (s fakeSender) Send(Alert) error { return s.err }
+
+type recordSender struct {
+	alerts []Alert
+}
+
+func (s *recordSender) Send(alert Alert) error {
+	s.alerts = append(s.alerts, alert)
+	return nil
+}
+
+type unnamedSender struct{}
+
+func (unnamedSender) Send(Alert) error { return nil }
+
+func TestSendMetrics(t *testing.T) {
+	defer func(r []Sender) { registry = r }(registry)
+	registry = []Sender{fakeSender{}, fakeSender{err: errors.New("unavailable")}, &unnamedSender{}}
+
+	assert.Error(t, Send(context.Background(), New("msg", "reason", "path", "proc")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(metrics.AlertsSent.WithLabelValues("fake", metrics.ResultSuccess)))
+	assert.Equal(t, 1.0, testutil.ToFloat64(metrics.AlertsSent.WithLabelValues("fake", metrics.ResultFailure)))
+	assert.Equal(t, 1.0, testutil.ToFloat64(metrics.AlertsSent.WithLabelValues("alerts.unnamedSender", metrics.ResultSuccess)))
+}
+
+func TestSendTraceID(t *testing.T) {
+	defer func(r []Sender) { registry = r }(registry)
+	rec := &recordSender{}
+	registry = []Sender{rec}
+
+	assert.NoError(t, Send(context.Background(), New("msg", "reason", "path", "proc")))
+	assert.Empty(t, rec.alerts[0].TraceID)
+
+	tp := sdktrace.NewTracerProvider()
+	defer func() { _ = tp.Shutdown(context.Background()) }()
+	ctx, span := tp.Tracer("test").Start(context.Background(), "scan")
+	defer span.End()
+	defer func(p trace.TracerProvider) { otel.SetTracerProvider(p) }(otel.GetTracerProvider())
+	otel.SetTracerProvider(tp)
+
+	assert.NoError(t, Send(ctx, New("msg", "reason", "path", "proc")))
+	assert.Equal(t, span.SpanContext().TraceID().String(), rec.alerts[1].TraceID)
+}
@@ -14,9 +14,12 @@ import (
 )
 
 type event struct {
-	Message string `json:"message"`
-	Reason  string `json:"reason"`
-	Path    string `json:"path"`
+	Message  string `json:"message"`
+	Reason   string `json:"reason"`
+	Path     string `json:"path"`
+	Audit    bool   `json:"audit,omitempty"`
+	Evidence string `json:"evidence,omitempty"`
+	TraceID  string `json:"trace_id,omitempty"`
 }
 
 type eventHolder struct {
@@ -45,6 +48,8 @@ func New(logger *logrus.Logger, uri string, token string, insecureSkipVerify boo
 	}
 }
 
+func (c *splunkClient) Name() string { return "splunk" }
+
 func (c *splunkClient) Send(alert alerts.Alert) error {
 
 	eh := eventFromAlert(alert)
@@ -89,9 +94,12 @@ func eventFromAlert(alert alerts.Alert) eventHolder {
 	return eventHolder{
 		Time: float64(alert.Time.UnixNano()) / 1e9,
 		Event: event{
-			Message: alert.Message,
-			Reason:  alert.Reason,
-			Path:    alert.Path,
+			Message:  alert.Message,
+			Reason:   alert.Reason,
+			Path:     alert.Path,
+			Audit:    alert.Audit,
+			Evidence: alert.Evidence,
+			TraceID:  alert.TraceID,
 		},
 	}
 }
@@ -19,10 +19,26 @@ const (
 )
 
 var ErrToType = map[string]int{
-	"file content mismatch": 1,
-	"new file found":        2,
-	"file deleted":          3,
-	alerts.HeartbeatEvent:   4,
+	"file content mismatch":         1,
+	"new file found":                2,
+	"file deleted":                  3,
+	alerts.HeartbeatEvent:           4,
+	"multiple integrity violations": 5,
+	"file metadata mismatch":        6,
+	"symlink target changed":        7,
+	"new special file found":        8,
+	"directory added":               9,
+	"directory deleted":             10,
+	"file size limit exceeded":      11,
+	"deleted executable running":    12,
+	"executable not in snapshot":    13,
+	"unexpected process running":    14,
+	"unexpected listening socket":   15,
+	"baseline learned":              16,
+	"baseline updated":              17,
+	"files restored":                18,
+	"files restore failed":          19,
+	"response action failed":        20,
 }
 
 var _ alerts.Sender = (*SyslogClient)(nil)
@@ -60,6 +76,8 @@ func New(logger *logrus.Logger, network, addr string, priority syslog.Priority,
 	}
 }
 
+func (sl *SyslogClient) Name() string { return "syslog" }
+
 func (sl *SyslogClient) Send(alert alerts.Alert) error {
 	msg := sl.syslogMessage(alert)
 	nl := ""
@@ -92,9 +110,19 @@ func (sl *SyslogClient) syslogMessage(alert alerts.Alert) string {
 	// pod = host name
 	podName, _ := os.Hostname()
 	pn := alert.ProcessName
-	return fmt.Sprintf("time=%s event-type=%04d service=%s pod=%s image=%s namespace=%s cluster=%s message=%s file=%s reason=%s",
+	msg := fmt.Sprintf("time=%s event-type=%04d service=%s pod=%s image=%s namespace=%s cluster=%s message=%s file=%s reason=%s",
 		alert.Time.Format(time.Stamp), ErrToType[alert.Reason], pn, podName, viper.GetStringMapString("process-image")[pn],
 		viper.GetString("pod-namespace"), viper.GetString("cluster-name"), alert.Message, alert.Path, alert.Reason)
+	if alert.Audit {
+		msg += " audit=true"
+	}
+	if alert.Evidence != "" {
+		msg += " evidence=" + alert.Evidence
+	}
+	if alert.TraceID != "" {
+		msg += " trace_id=" + alert.TraceID
+	}
+	return msg
 }
 
 func (sl *SyslogClient) dial() (net.Conn, error) {
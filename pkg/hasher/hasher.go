@@ -1,7 +1,6 @@
 package hasher
 
 import (
-	"bytes"
 	"crypto/md5"
 	"crypto/sha1"
 	"crypto/sha256"
@@ -12,6 +11,7 @@ import (
 	"io"
 	"os"
 	"strings"
+	"sync"
 
 	"github.com/sirupsen/logrus"
 )
@@ -21,6 +21,16 @@ type FileHasher interface {
 	HashFile(fileName string) (string, error)
 }
 
+// bufSize is the size of the buffers used to stream files into hashers
+const bufSize = 128 * 1024
+
+var bufPool = sync.Pool{
+	New: func() any {
+		buf := make([]byte, bufSize)
+		return &buf
+	},
+}
+
 type Hasher struct {
 	h   hash.Hash
 	log *logrus.Logger
@@ -46,21 +56,50 @@ func (fh *Hasher) HashFile(fullFileName string) (string, error) {
 		return ownFileHasher.HashFile(fullFileName)
 	}
 
-	data, err := os.ReadFile(fullFileName)
+	f, err := os.Open(fullFileName)
 	if err != nil {
 		fh.log.WithError(err).Errorf("can not read from file %q", fullFileName)
 		return "", err
 	}
-	return fh.HashData(bytes.NewReader(data))
+	defer f.Close()
+	return fh.HashData(f)
+}
+
+// HashFileHeadTail calculates hash for the first and the last @limit/2 bytes
+// of a file, the whole file is hashed if it is not larger than @limit.
+func (fh *Hasher) HashFileHeadTail(fullFileName string, limit int64) (string, error) {
+	f, err := os.Open(fullFileName)
+	if err != nil {
+		fh.log.WithError(err).Errorf("can not read from file %q", fullFileName)
+		return "", err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return "", err
+	}
+	if info.Size() <= limit {
+		return fh.HashData(f)
+	}
+	half := limit / 2
+	return fh.HashData(io.MultiReader(
+		io.NewSectionReader(f, 0, half),
+		io.NewSectionReader(f, info.Size()-half, half),
+	))
 }
 
 // HashData calculates hash for a data represented with @r.
-// Uses default Go Hash interface.
-func (fh *Hasher) HashData(r *bytes.Reader) (string, error) {
+// Uses default Go Hash interface, the data is streamed through a pooled buffer.
+func (fh *Hasher) HashData(r io.Reader) (string, error) {
+	buf := bufPool.Get().(*[]byte)
+	defer bufPool.Put(buf)
+
 	fh.h.Reset()
-	_, err := io.Copy(fh.h, r)
+	// hides io.WriterTo of the reader, otherwise the buffer is not used
+	_, err := io.CopyBuffer(fh.h, struct{ io.Reader }{r}, *buf)
 	if err != nil {
-		fh.log.WithError(err).Error("io.Copy()")
+		fh.log.WithError(err).Error("io.CopyBuffer()")
 		return "", err
 	}
 	return hex.EncodeToString(fh.h.Sum(nil)), nil
@@ -89,7 +128,7 @@ func newHasherInstance(algName string) hash.Hash {
 }
 
 // Returns new FileHasher instance
-func NewFileHasher(algName string, log *logrus.Logger) FileHasher {
+func NewFileHasher(algName string, log *logrus.Logger) *Hasher {
 	return &Hasher{
 		h:   newHasherInstance(algName),
 		log: log,
@@ -56,6 +56,26 @@ func testRepeated(t *testing.T, fileName string) {
 	assert.Equal(t, expectedValues[alg], hash, "alg: %s", alg)
 }
 
+func TestHashFileHeadTail(t *testing.T) {
+	fileName, err := createTmpFile(bytes.NewBufferString("headMIDDLEtail"))
+	assert.NoError(t, err, "test file creation")
+	defer os.Remove(fileName)
+
+	hasher := NewFileHasher("SHA256", logrus.New())
+	hash, err := hasher.HashFileHeadTail(fileName, 8)
+	assert.NoError(t, err)
+	expected, err := hasher.HashData(bytes.NewBufferString("headtail"))
+	assert.NoError(t, err)
+	assert.Equal(t, expected, hash)
+
+	// the whole file is hashed if it fits the limit
+	hash, err = hasher.HashFileHeadTail(fileName, 100)
+	assert.NoError(t, err)
+	expected, err = hasher.HashFile(fileName)
+	assert.NoError(t, err)
+	assert.Equal(t, expected, hash)
+}
+
 // Creates temporary file with data in @buf
 func createTmpFile(buf *bytes.Buffer) (string, error) {
 	f, err := os.CreateTemp("/tmp", "test_")
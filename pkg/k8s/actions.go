@@ -0,0 +1,132 @@
+package k8s
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const (
+	QuarantineLabel = "integrity-monitor.scnsoft.com/quarantine"
+
+	kindDeployment  = "Deployment"
+	kindReplicaSet  = "ReplicaSet"
+	kindStatefulSet = "StatefulSet"
+)
+
+// EvictPod evicts pod through the Eviction API, so PodDisruptionBudgets are
+// respected
+func (ks *KubeClient) EvictPod(ctx context.Context) error {
+	err := ks.clientset.PolicyV1().Evictions(kubeData.PodNamespace).Evict(ctx, &policyv1.Eviction{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      kubeData.PodName,
+			Namespace: kubeData.PodNamespace,
+		},
+	})
+	if err != nil {
+		ks.logger.Printf("### 👎 Warning: Failed to evict pod %v: %v", kubeData.PodName, err)
+		return err
+	}
+
+	ks.logger.Printf("### ✅ Pod %v was evicted", kubeData.PodName)
+	return nil
+}
+
+// ScaleOwnerToZero scales the workload owning the pod (Deployment, StatefulSet
+// or standalone ReplicaSet) down to zero replicas
+func (ks *KubeClient) ScaleOwnerToZero(ctx context.Context) error {
+	owner, err := ks.podOwner(ctx)
+	if err != nil {
+		ks.logger.Printf("### 👎 Warning: Failed to find owner of pod %v: %v", kubeData.PodName, err)
+		return err
+	}
+	kind, name := owner.Kind, owner.Name
+
+	apps := ks.clientset.AppsV1()
+	var (
+		getScale    func(context.Context, string, metav1.GetOptions) (*autoscalingv1.Scale, error)
+		updateScale func(context.Context, string, *autoscalingv1.Scale, metav1.UpdateOptions) (*autoscalingv1.Scale, error)
+	)
+	switch kind {
+	case kindDeployment:
+		getScale, updateScale = apps.Deployments(kubeData.PodNamespace).GetScale, apps.Deployments(kubeData.PodNamespace).UpdateScale
+	case kindStatefulSet:
+		getScale, updateScale = apps.StatefulSets(kubeData.PodNamespace).GetScale, apps.StatefulSets(kubeData.PodNamespace).UpdateScale
+	case kindReplicaSet:
+		getScale, updateScale = apps.ReplicaSets(kubeData.PodNamespace).GetScale, apps.ReplicaSets(kubeData.PodNamespace).UpdateScale
+	default:
+		return fmt.Errorf("unsupported owner kind %q of pod %s", kind, kubeData.PodName)
+	}
+
+	scale, err := getScale(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		ks.logger.Printf("### 👎 Warning: Failed to get scale of %s %v: %v", kind, name, err)
+		return err
+	}
+	scale.Spec.Replicas = 0
+	if _, err = updateScale(ctx, name, scale, metav1.UpdateOptions{}); err != nil {
+		ks.logger.Printf("### 👎 Warning: Failed to scale %s %v to zero: %v", kind, name, err)
+		return err
+	}
+
+	ks.logger.Printf("### ✅ %s %v was scaled to zero", kind, name)
+	return nil
+}
+
+// QuarantinePod marks the pod with the quarantine label
+func (ks *KubeClient) QuarantinePod(ctx context.Context) error {
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"labels": map[string]string{QuarantineLabel: "true"},
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = ks.clientset.CoreV1().Pods(kubeData.PodNamespace).Patch(ctx,
+		kubeData.PodName, types.StrategicMergePatchType, patch, metav1.PatchOptions{})
+	if err != nil {
+		ks.logger.Printf("### 👎 Warning: Failed to quarantine pod %v: %v", kubeData.PodName, err)
+		return err
+	}
+
+	ks.logger.Printf("### ✅ Pod %v was labeled as quarantined", kubeData.PodName)
+	return nil
+}
+
+// podOwner returns the reference to the top-level controller of the pod. A
+// ReplicaSet owned by a Deployment is resolved to that Deployment.
+func (ks *KubeClient) podOwner(ctx context.Context) (*metav1.OwnerReference, error) {
+	pod, err := ks.clientset.CoreV1().Pods(kubeData.PodNamespace).Get(ctx, kubeData.PodName, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return ks.ownerOf(ctx, pod)
+}
+
+// ownerOf returns the reference to the top-level controller of the @pod.
+func (ks *KubeClient) ownerOf(ctx context.Context, pod *corev1.Pod) (*metav1.OwnerReference, error) {
+	owner := metav1.GetControllerOf(pod)
+	if owner == nil {
+		return nil, fmt.Errorf("pod %s has no controller", pod.Name)
+	}
+	if owner.Kind != kindReplicaSet {
+		return owner, nil
+	}
+
+	rs, err := ks.clientset.AppsV1().ReplicaSets(pod.Namespace).Get(ctx, owner.Name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	if rsOwner := metav1.GetControllerOf(rs); rsOwner != nil && rsOwner.Kind == kindDeployment {
+		return rsOwner, nil
+	}
+	return owner, nil
+}
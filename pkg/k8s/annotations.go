@@ -0,0 +1,66 @@
+package k8s
+
+import (
+	"context"
+	"encoding/json"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const (
+	// AnnotationRebaseline requests to approve the current state of the pod as
+	// the new baseline, the value names the requester. It is not verified, the
+	// user who set it is in the audit log of the cluster
+	AnnotationRebaseline = "integrity-monitor.scnsoft.com/rebaseline"
+	// AnnotationRebaselineProcesses limits the rebaseline to the listed
+	// comma separated processes
+	AnnotationRebaselineProcesses = "integrity-monitor.scnsoft.com/rebaseline-processes"
+	// AnnotationRebaselineStatus holds the result of the last rebaseline
+	AnnotationRebaselineStatus = "integrity-monitor.scnsoft.com/rebaseline-status"
+
+	annotationLastScan       = "integrity-monitor.scnsoft.com/last-scan"
+	annotationLastScanResult = "integrity-monitor.scnsoft.com/last-scan-result"
+)
+
+// LastScanAnnotation returns the annotation holding the time of the last scan
+// of the @procName process
+func LastScanAnnotation(procName string) string {
+	return procName + "." + annotationLastScan
+}
+
+// LastScanResultAnnotation returns the annotation holding the result of the
+// last scan of the @procName process
+func LastScanResultAnnotation(procName string) string {
+	return procName + "." + annotationLastScanResult
+}
+
+// GetPodAnnotations returns the annotations of the pod
+func (ks *KubeClient) GetPodAnnotations(ctx context.Context) (map[string]string, error) {
+	pod, err := ks.clientset.CoreV1().Pods(kubeData.PodNamespace).Get(ctx, kubeData.PodName, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return pod.Annotations, nil
+}
+
+// AnnotatePod sets the @annotations of the pod, the annotations with nil
+// values are removed
+func (ks *KubeClient) AnnotatePod(ctx context.Context, annotations map[string]*string) error {
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": annotations,
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = ks.clientset.CoreV1().Pods(kubeData.PodNamespace).Patch(ctx,
+		kubeData.PodName, types.MergePatchType, patch, metav1.PatchOptions{})
+	if err != nil {
+		ks.logger.Printf("### 👎 Warning: Failed to annotate pod %v: %v", kubeData.PodName, err)
+		return err
+	}
+	return nil
+}
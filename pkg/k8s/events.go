@@ -0,0 +1,78 @@
+package k8s
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/ScienceSoft-Inc/integrity-sum/pkg/common"
+)
+
+const (
+	// EventReasonIntegrityViolation is the reason of the events about the
+	// integrity violations found in the pod
+	EventReasonIntegrityViolation = "IntegrityViolation"
+
+	// maxEventMessage is the length limit of the event message, the same as
+	// the client-go event recorder applies
+	maxEventMessage = 1024
+)
+
+// EmitWarningEvent creates the Warning event with the @reason and @message on
+// the pod and on the top-level controller owning it, so the event is shown by
+// `kubectl describe` of both of them
+func (ks *KubeClient) EmitWarningEvent(ctx context.Context, reason, message string) error {
+	pod, err := ks.clientset.CoreV1().Pods(kubeData.PodNamespace).Get(ctx, kubeData.PodName, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+
+	refs := []corev1.ObjectReference{{
+		APIVersion: "v1",
+		Kind:       "Pod",
+		Namespace:  pod.Namespace,
+		Name:       pod.Name,
+		UID:        pod.UID,
+	}}
+	owner, err := ks.ownerOf(ctx, pod)
+	if err != nil {
+		ks.logger.Printf("### 👎 Warning: Failed to find owner of pod %v, the event is created for the pod only: %v", pod.Name, err)
+	} else {
+		refs = append(refs, corev1.ObjectReference{
+			APIVersion: owner.APIVersion,
+			Kind:       owner.Kind,
+			Namespace:  pod.Namespace,
+			Name:       owner.Name,
+			UID:        owner.UID,
+		})
+	}
+
+	if len(message) > maxEventMessage {
+		message = message[:maxEventMessage-3] + "..."
+	}
+	now := metav1.Now()
+	for _, ref := range refs {
+		event := &corev1.Event{
+			ObjectMeta: metav1.ObjectMeta{
+				GenerateName: ref.Name + ".",
+				Namespace:    pod.Namespace,
+			},
+			InvolvedObject:      ref,
+			Reason:              reason,
+			Message:             message,
+			Type:                corev1.EventTypeWarning,
+			Source:              corev1.EventSource{Component: common.AppId, Host: pod.Spec.NodeName},
+			FirstTimestamp:      now,
+			LastTimestamp:       now,
+			Count:               1,
+			ReportingController: common.AppId,
+			ReportingInstance:   pod.Name,
+		}
+		if _, err = ks.clientset.CoreV1().Events(pod.Namespace).Create(ctx, event, metav1.CreateOptions{}); err != nil {
+			ks.logger.Printf("### 👎 Warning: Failed to create event for %s %v: %v", ref.Kind, ref.Name, err)
+			return err
+		}
+	}
+	return nil
+}
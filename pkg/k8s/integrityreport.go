@@ -0,0 +1,158 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/util/retry"
+)
+
+// IntegrityReportGVR is the resource of the IntegrityReport CRD, the reports
+// are aggregated onto the matching Snapshot by the snapshot-controller
+var IntegrityReportGVR = schema.GroupVersionResource{Group: "integrity.snapshot", Version: "v1", Resource: "integrityreports"}
+
+// maxReportViolations limits the violations stored in the report, the CR
+// size is limited by etcd
+const maxReportViolations = 100
+
+// IntegrityReport is the outcome of the last scan of a process
+type IntegrityReport struct {
+	Process    string
+	Image      string
+	Algorithm  string
+	Result     string
+	Time       time.Time
+	Audit      bool
+	Error      string
+	Violations []ReportViolation
+}
+
+// ReportViolation is an integrity violation of the IntegrityReport
+type ReportViolation struct {
+	Reason   string
+	Path     string
+	Expected string
+	Actual   string
+}
+
+// IntegrityReportName returns the name of the IntegrityReport CR of the
+// @procName process of the @podName pod, e.g. app-6d4cf56db6-x2x7l-nginx.
+func IntegrityReportName(podName, procName string) string {
+	name := strings.Trim(invalidNameChars.ReplaceAllString(strings.ToLower(podName+"-"+procName), "-"), "-.")
+	if len(name) > 253 {
+		name = strings.TrimRight(name[:253], "-.")
+	}
+	return name
+}
+
+// UpdateIntegrityReport stores the @report in the IntegrityReport CR of the
+// process. The CR is created on the first report and owned by the pod, so it
+// is removed along with the pod. The update is retried on conflicts, so the
+// scan counts are not lost.
+func (ks *KubeClient) UpdateIntegrityReport(ctx context.Context, report IntegrityReport) error {
+	resource := ks.dynamic.Resource(IntegrityReportGVR).Namespace(kubeData.PodNamespace)
+	name := IntegrityReportName(kubeData.PodName, report.Process)
+
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		obj, err := resource.Get(ctx, name, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			obj, err = ks.createIntegrityReport(ctx, name, report)
+		}
+		if err != nil {
+			return err
+		}
+		if err := setIntegrityReportStatus(obj, report); err != nil {
+			return err
+		}
+		_, err = resource.UpdateStatus(ctx, obj, metav1.UpdateOptions{})
+		return err
+	})
+	if err != nil {
+		ks.logger.Printf("### 👎 Warning: Failed to update integrity report %v: %v", name, err)
+		return err
+	}
+	return nil
+}
+
+// setIntegrityReportStatus sets the status of the IntegrityReport @obj to the
+// @report, the scan count of the report result is incremented.
+func setIntegrityReportStatus(obj *unstructured.Unstructured, report IntegrityReport) error {
+	scans, _, err := unstructured.NestedMap(obj.Object, "status", "scans")
+	if err != nil {
+		return fmt.Errorf("integrity report %s: %w", obj.GetName(), err)
+	}
+	if scans == nil {
+		scans = map[string]interface{}{}
+	}
+	count, _, err := unstructured.NestedInt64(scans, report.Result)
+	if err != nil {
+		return fmt.Errorf("integrity report %s: %w", obj.GetName(), err)
+	}
+	scans[report.Result] = count + 1
+
+	violations := make([]interface{}, 0, len(report.Violations))
+	for i, v := range report.Violations {
+		if i == maxReportViolations {
+			break
+		}
+		violations = append(violations, map[string]interface{}{
+			"reason":   v.Reason,
+			"path":     v.Path,
+			"expected": v.Expected,
+			"actual":   v.Actual,
+		})
+	}
+	obj.Object["status"] = map[string]interface{}{
+		"result":         report.Result,
+		"lastScan":       report.Time.UTC().Format(time.RFC3339),
+		"audit":          report.Audit,
+		"error":          report.Error,
+		"scans":          scans,
+		"violationCount": int64(len(report.Violations)),
+		"violations":     violations,
+	}
+	return nil
+}
+
+// createIntegrityReport creates the @name IntegrityReport CR owned by the pod
+// for the @report process.
+func (ks *KubeClient) createIntegrityReport(ctx context.Context, name string, report IntegrityReport) (*unstructured.Unstructured, error) {
+	pod, err := ks.clientset.CoreV1().Pods(kubeData.PodNamespace).Get(ctx, kubeData.PodName, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": IntegrityReportGVR.GroupVersion().String(),
+		"kind":       "IntegrityReport",
+		"metadata": map[string]interface{}{
+			"name":      name,
+			"namespace": kubeData.PodNamespace,
+			"labels": map[string]interface{}{
+				"app.kubernetes.io/name":       "integrityreport",
+				"app.kubernetes.io/created-by": "integrity-monitor",
+			},
+			"ownerReferences": []interface{}{
+				map[string]interface{}{
+					"apiVersion": "v1",
+					"kind":       "Pod",
+					"name":       pod.Name,
+					"uid":        string(pod.UID),
+				},
+			},
+		},
+		"spec": map[string]interface{}{
+			"podName":   pod.Name,
+			"process":   report.Process,
+			"image":     report.Image,
+			"algorithm": strings.ToLower(report.Algorithm),
+		},
+	}}
+	return ks.dynamic.Resource(IntegrityReportGVR).Namespace(kubeData.PodNamespace).Create(ctx, obj, metav1.CreateOptions{})
+}
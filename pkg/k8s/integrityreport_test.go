@@ -0,0 +1,35 @@
+package k8s
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestSetIntegrityReportStatus(t *testing.T) {
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{}}
+	report := IntegrityReport{Process: "nginx", Result: "ok", Time: time.Now()}
+	require.NoError(t, setIntegrityReportStatus(obj, report))
+	require.NoError(t, setIntegrityReportStatus(obj, report))
+
+	report.Result = "violation"
+	report.Violations = make([]ReportViolation, maxReportViolations+1)
+	require.NoError(t, setIntegrityReportStatus(obj, report))
+
+	scans, _, err := unstructured.NestedMap(obj.Object, "status", "scans")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"ok": int64(2), "violation": int64(1)}, scans)
+	count, _, err := unstructured.NestedInt64(obj.Object, "status", "violationCount")
+	require.NoError(t, err)
+	assert.EqualValues(t, maxReportViolations+1, count)
+	violations, _, err := unstructured.NestedSlice(obj.Object, "status", "violations")
+	require.NoError(t, err)
+	assert.Len(t, violations, maxReportViolations)
+
+	// the count of an unexpected type is not reset silently
+	obj.Object["status"].(map[string]interface{})["scans"] = map[string]interface{}{"violation": "2"}
+	assert.Error(t, setIntegrityReportStatus(obj, report))
+}
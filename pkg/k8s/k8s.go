@@ -9,10 +9,13 @@ import (
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/viper"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 
 	"github.com/ScienceSoft-Inc/integrity-sum/internal/logger"
+	"github.com/ScienceSoft-Inc/integrity-sum/pkg/metrics"
+	"github.com/ScienceSoft-Inc/integrity-sum/pkg/tracing"
 )
 
 //go:generate mockgen -source=k8s.go -destination=mocks/mock_k8s.go
@@ -20,7 +23,17 @@ import (
 type IKuberService interface {
 	Connect() error
 	GetDataFromDeployment() (*DeploymentData, error)
-	RestartPod() error
+	RestartPod(ctx context.Context) error
+	EvictPod(ctx context.Context) error
+	ScaleOwnerToZero(ctx context.Context) error
+	QuarantinePod(ctx context.Context) error
+	RollbackDeployment(ctx context.Context) error
+	CreateSnapshot(ctx context.Context, image, alg string, hashes []byte) (string, error)
+	GetPodAnnotations(ctx context.Context) (map[string]string, error)
+	AnnotatePod(ctx context.Context, annotations map[string]*string) error
+	EmitWarningEvent(ctx context.Context, reason, message string) error
+	UpdateIntegrityReport(ctx context.Context, report IntegrityReport) error
+	AuthenticateToken(ctx context.Context, token string) (string, error)
 }
 
 type KubeData struct {
@@ -43,6 +56,7 @@ type DeploymentData struct {
 type KubeClient struct {
 	logger    *logrus.Logger
 	clientset *kubernetes.Clientset
+	dynamic   dynamic.Interface
 }
 
 var kubeData *KubeData
@@ -95,6 +109,7 @@ func (ks *KubeClient) Connect() error {
 	}
 
 	ks.logger.Info("### 💻 Connecting to Kubernetes API, using host: ", config.Host)
+	config.Wrap(tracing.Transport)
 	clientset, err := kubernetes.NewForConfig(config)
 	if err != nil {
 		ks.logger.Error(err)
@@ -102,6 +117,13 @@ func (ks *KubeClient) Connect() error {
 	}
 	ks.clientset = clientset
 
+	dynamicClient, err := dynamic.NewForConfig(config)
+	if err != nil {
+		ks.logger.Error(err)
+		return err
+	}
+	ks.dynamic = dynamicClient
+
 	return nil
 }
 
@@ -137,15 +159,16 @@ func (ks *KubeClient) GetDataFromDeployment() (*DeploymentData, error) {
 }
 
 // RestartPod restarts pod
-func (ks *KubeClient) RestartPod() error {
+func (ks *KubeClient) RestartPod(ctx context.Context) error {
 	// Deleting pod to force a restart
-	err := ks.clientset.CoreV1().Pods(kubeData.PodNamespace).Delete(context.Background(), kubeData.PodName, metav1.DeleteOptions{})
+	err := ks.clientset.CoreV1().Pods(kubeData.PodNamespace).Delete(ctx, kubeData.PodName, metav1.DeleteOptions{})
 
 	if err != nil {
 		ks.logger.Printf("### 👎 Warning: Failed to delete pod %v, restart failed: %v", kubeData.PodName, err)
 		return err
 	}
 
+	metrics.PodRestarts.Inc()
 	ks.logger.Printf("### ✅ Pod %v was forced to be restartd", kubeData.PodName)
 	return nil
 }
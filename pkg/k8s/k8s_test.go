@@ -1,6 +1,7 @@
 package k8s_test
 
 import (
+	"context"
 	"github.com/ScienceSoft-Inc/integrity-sum/pkg/k8s"
 	mockk8s "github.com/ScienceSoft-Inc/integrity-sum/pkg/k8s/mocks"
 	"github.com/golang/mock/gomock"
@@ -16,6 +17,8 @@ func TestKubeClient(t *testing.T) {
 	// Create a mock implementation of the IKuberService interface
 	mockService := mockk8s.NewMockIKuberService(ctrl)
 
+	ctx := context.Background()
+
 	// Define test data
 	deploymentData := &k8s.DeploymentData{
 		Image:          "test-image",
@@ -38,7 +41,48 @@ func TestKubeClient(t *testing.T) {
 	assert.Equal(t, deploymentData, data)
 
 	// Test the RestartPod method
-	mockService.EXPECT().RestartPod().Return(nil)
-	err = mockService.RestartPod()
+	mockService.EXPECT().RestartPod(ctx).Return(nil)
+	err = mockService.RestartPod(ctx)
+	assert.NoError(t, err)
+
+	// Test the response action methods
+	mockService.EXPECT().EvictPod(ctx).Return(nil)
+	assert.NoError(t, mockService.EvictPod(ctx))
+	mockService.EXPECT().ScaleOwnerToZero(ctx).Return(nil)
+	assert.NoError(t, mockService.ScaleOwnerToZero(ctx))
+	mockService.EXPECT().QuarantinePod(ctx).Return(nil)
+	assert.NoError(t, mockService.QuarantinePod(ctx))
+	mockService.EXPECT().RollbackDeployment(ctx).Return(nil)
+	assert.NoError(t, mockService.RollbackDeployment(ctx))
+
+	// Test the CreateSnapshot method
+	mockService.EXPECT().CreateSnapshot(ctx, "nginx:1.25", "sha256", []byte("hashes")).Return("learned-nginx-1.25-sha256", nil)
+	name, err := mockService.CreateSnapshot(ctx, "nginx:1.25", "sha256", []byte("hashes"))
+	assert.NoError(t, err)
+	assert.Equal(t, "learned-nginx-1.25-sha256", name)
+
+	// Test the pod annotation methods
+	annotations := map[string]string{k8s.AnnotationRebaseline: "jane.doe"}
+	mockService.EXPECT().GetPodAnnotations(ctx).Return(annotations, nil)
+	got, err := mockService.GetPodAnnotations(ctx)
 	assert.NoError(t, err)
+	assert.Equal(t, annotations, got)
+	mockService.EXPECT().AnnotatePod(ctx, map[string]*string{k8s.AnnotationRebaseline: nil}).Return(nil)
+	assert.NoError(t, mockService.AnnotatePod(ctx, map[string]*string{k8s.AnnotationRebaseline: nil}))
+
+	// Test the EmitWarningEvent method
+	mockService.EXPECT().EmitWarningEvent(ctx, k8s.EventReasonIntegrityViolation, "process nginx: file deleted etc/a").Return(nil)
+	assert.NoError(t, mockService.EmitWarningEvent(ctx, k8s.EventReasonIntegrityViolation, "process nginx: file deleted etc/a"))
+}
+
+func TestLearnedSnapshotName(t *testing.T) {
+	assert.Equal(t, "learned-nginx-1.25-sha256", k8s.LearnedSnapshotName("nginx:1.25", "sha256"))
+	assert.Equal(t, "learned-registry.local-5000-team-app-v1-sha256",
+		k8s.LearnedSnapshotName("registry.local:5000/team/app:v1", "SHA256"))
+}
+
+func TestIntegrityReportName(t *testing.T) {
+	assert.Equal(t, "app-6d4cf56db6-x2x7l-nginx", k8s.IntegrityReportName("app-6d4cf56db6-x2x7l", "nginx"))
+	assert.Equal(t, "app-0-python3.11", k8s.IntegrityReportName("app-0", "Python3.11"))
+	assert.Equal(t, "app-0-my-app", k8s.IntegrityReportName("app-0", "my_app_"))
 }
@@ -5,6 +5,7 @@
 package mock_k8s
 
 import (
+	context "context"
 	reflect "reflect"
 
 	k8s "github.com/ScienceSoft-Inc/integrity-sum/pkg/k8s"
@@ -34,6 +35,35 @@ func (m *MockIKuberService) EXPECT() *MockIKuberServiceMockRecorder {
 	return m.recorder
 }
 
+// AnnotatePod mocks base method.
+func (m *MockIKuberService) AnnotatePod(ctx context.Context, annotations map[string]*string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AnnotatePod", ctx, annotations)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AnnotatePod indicates an expected call of AnnotatePod.
+func (mr *MockIKuberServiceMockRecorder) AnnotatePod(ctx, annotations interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AnnotatePod", reflect.TypeOf((*MockIKuberService)(nil).AnnotatePod), ctx, annotations)
+}
+
+// AuthenticateToken mocks base method.
+func (m *MockIKuberService) AuthenticateToken(ctx context.Context, token string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AuthenticateToken", ctx, token)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AuthenticateToken indicates an expected call of AuthenticateToken.
+func (mr *MockIKuberServiceMockRecorder) AuthenticateToken(ctx, token interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AuthenticateToken", reflect.TypeOf((*MockIKuberService)(nil).AuthenticateToken), ctx, token)
+}
+
 // Connect mocks base method.
 func (m *MockIKuberService) Connect() error {
 	m.ctrl.T.Helper()
@@ -48,6 +78,49 @@ func (mr *MockIKuberServiceMockRecorder) Connect() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Connect", reflect.TypeOf((*MockIKuberService)(nil).Connect))
 }
 
+// CreateSnapshot mocks base method.
+func (m *MockIKuberService) CreateSnapshot(ctx context.Context, image, alg string, hashes []byte) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateSnapshot", ctx, image, alg, hashes)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateSnapshot indicates an expected call of CreateSnapshot.
+func (mr *MockIKuberServiceMockRecorder) CreateSnapshot(ctx, image, alg, hashes interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSnapshot", reflect.TypeOf((*MockIKuberService)(nil).CreateSnapshot), ctx, image, alg, hashes)
+}
+
+// EmitWarningEvent mocks base method.
+func (m *MockIKuberService) EmitWarningEvent(ctx context.Context, reason, message string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EmitWarningEvent", ctx, reason, message)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// EmitWarningEvent indicates an expected call of EmitWarningEvent.
+func (mr *MockIKuberServiceMockRecorder) EmitWarningEvent(ctx, reason, message interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EmitWarningEvent", reflect.TypeOf((*MockIKuberService)(nil).EmitWarningEvent), ctx, reason, message)
+}
+
+// EvictPod mocks base method.
+func (m *MockIKuberService) EvictPod(ctx context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EvictPod", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// EvictPod indicates an expected call of EvictPod.
+func (mr *MockIKuberServiceMockRecorder) EvictPod(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EvictPod", reflect.TypeOf((*MockIKuberService)(nil).EvictPod), ctx)
+}
+
 // GetDataFromDeployment mocks base method.
 func (m *MockIKuberService) GetDataFromDeployment() (*k8s.DeploymentData, error) {
 	m.ctrl.T.Helper()
@@ -63,16 +136,87 @@ func (mr *MockIKuberServiceMockRecorder) GetDataFromDeployment() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDataFromDeployment", reflect.TypeOf((*MockIKuberService)(nil).GetDataFromDeployment))
 }
 
+// GetPodAnnotations mocks base method.
+func (m *MockIKuberService) GetPodAnnotations(ctx context.Context) (map[string]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPodAnnotations", ctx)
+	ret0, _ := ret[0].(map[string]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPodAnnotations indicates an expected call of GetPodAnnotations.
+func (mr *MockIKuberServiceMockRecorder) GetPodAnnotations(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPodAnnotations", reflect.TypeOf((*MockIKuberService)(nil).GetPodAnnotations), ctx)
+}
+
+// QuarantinePod mocks base method.
+func (m *MockIKuberService) QuarantinePod(ctx context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "QuarantinePod", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// QuarantinePod indicates an expected call of QuarantinePod.
+func (mr *MockIKuberServiceMockRecorder) QuarantinePod(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "QuarantinePod", reflect.TypeOf((*MockIKuberService)(nil).QuarantinePod), ctx)
+}
+
 // RestartPod mocks base method.
-func (m *MockIKuberService) RestartPod() error {
+func (m *MockIKuberService) RestartPod(ctx context.Context) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "RestartPod")
+	ret := m.ctrl.Call(m, "RestartPod", ctx)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // RestartPod indicates an expected call of RestartPod.
-func (mr *MockIKuberServiceMockRecorder) RestartPod() *gomock.Call {
+func (mr *MockIKuberServiceMockRecorder) RestartPod(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestartPod", reflect.TypeOf((*MockIKuberService)(nil).RestartPod), ctx)
+}
+
+// RollbackDeployment mocks base method.
+func (m *MockIKuberService) RollbackDeployment(ctx context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RollbackDeployment", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RollbackDeployment indicates an expected call of RollbackDeployment.
+func (mr *MockIKuberServiceMockRecorder) RollbackDeployment(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RollbackDeployment", reflect.TypeOf((*MockIKuberService)(nil).RollbackDeployment), ctx)
+}
+
+// ScaleOwnerToZero mocks base method.
+func (m *MockIKuberService) ScaleOwnerToZero(ctx context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ScaleOwnerToZero", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ScaleOwnerToZero indicates an expected call of ScaleOwnerToZero.
+func (mr *MockIKuberServiceMockRecorder) ScaleOwnerToZero(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ScaleOwnerToZero", reflect.TypeOf((*MockIKuberService)(nil).ScaleOwnerToZero), ctx)
+}
+
+// UpdateIntegrityReport mocks base method.
+func (m *MockIKuberService) UpdateIntegrityReport(ctx context.Context, report k8s.IntegrityReport) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateIntegrityReport", ctx, report)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateIntegrityReport indicates an expected call of UpdateIntegrityReport.
+func (mr *MockIKuberServiceMockRecorder) UpdateIntegrityReport(ctx, report interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestartPod", reflect.TypeOf((*MockIKuberService)(nil).RestartPod))
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateIntegrityReport", reflect.TypeOf((*MockIKuberService)(nil).UpdateIntegrityReport), ctx, report)
 }
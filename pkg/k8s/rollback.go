@@ -0,0 +1,170 @@
+package k8s
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
+)
+
+// Annotations which record the rollbacks performed by the integrity monitor
+const (
+	RevisionAnnotation          = "deployment.kubernetes.io/revision"
+	RollbackFromAnnotation      = "integrity-monitor.scnsoft.com/rollback-from-revision"
+	RollbackToAnnotation        = "integrity-monitor.scnsoft.com/rollback-to-revision"
+	RollbackTimeAnnotation      = "integrity-monitor.scnsoft.com/rollback-time"
+	TamperedTemplatesAnnotation = "integrity-monitor.scnsoft.com/tampered-templates"
+)
+
+// ErrNoHealthyRevision - there is no earlier revision which was not tampered
+var ErrNoHealthyRevision = errors.New("no earlier healthy revision to roll back to")
+
+// RollbackDeployment rolls the Deployment owning the pod back to its previous
+// revision like `kubectl rollout undo` does. The pod template of the
+// ReplicaSet of the pod and the templates of revisions that were found
+// tampered earlier are recorded in the deployment annotations and never used
+// as the rollback target again, so the rollback does not loop.
+func (ks *KubeClient) RollbackDeployment(ctx context.Context) error {
+	podRS, err := ks.podReplicaSet(ctx)
+	if err != nil {
+		ks.logger.Printf("### 👎 Warning: Failed to find replica set of pod %v: %v", kubeData.PodName, err)
+		return err
+	}
+	owner := metav1.GetControllerOf(podRS)
+	if owner == nil || owner.Kind != kindDeployment {
+		return fmt.Errorf("pod %s is owned by ReplicaSet %s without deployment, rollback is supported for deployments only", kubeData.PodName, podRS.Name)
+	}
+	name := owner.Name
+
+	var from, to string
+	deployments := ks.clientset.AppsV1().Deployments(kubeData.PodNamespace)
+	err = retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		deployment, err := deployments.Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+
+		selector, err := metav1.LabelSelectorAsSelector(deployment.Spec.Selector)
+		if err != nil {
+			return err
+		}
+		rsList, err := ks.clientset.AppsV1().ReplicaSets(kubeData.PodNamespace).List(ctx, metav1.ListOptions{
+			LabelSelector: selector.String(),
+		})
+		if err != nil {
+			return err
+		}
+
+		var owned []appsv1.ReplicaSet
+		for _, rs := range rsList.Items {
+			if metav1.IsControlledBy(&rs, deployment) {
+				owned = append(owned, rs)
+			}
+		}
+
+		if from, to, err = rollbackTemplate(deployment, owned, podRS); err != nil {
+			return err
+		}
+		_, err = deployments.Update(ctx, deployment, metav1.UpdateOptions{})
+		return err
+	})
+	if err != nil {
+		ks.logger.Printf("### 👎 Warning: Failed to roll back deployment %v: %v", name, err)
+		return err
+	}
+
+	ks.logger.Printf("### ✅ Deployment %v was rolled back from revision %v to %v", name, from, to)
+	return nil
+}
+
+// podReplicaSet returns the ReplicaSet controlling the pod.
+func (ks *KubeClient) podReplicaSet(ctx context.Context) (*appsv1.ReplicaSet, error) {
+	pod, err := ks.clientset.CoreV1().Pods(kubeData.PodNamespace).Get(ctx, kubeData.PodName, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	owner := metav1.GetControllerOf(pod)
+	if owner == nil || owner.Kind != kindReplicaSet {
+		return nil, fmt.Errorf("pod %s is not controlled by a replica set", pod.Name)
+	}
+	return ks.clientset.AppsV1().ReplicaSets(pod.Namespace).Get(ctx, owner.Name, metav1.GetOptions{})
+}
+
+// rollbackTemplate sets the pod template of the @deployment to the one of the
+// latest revision among its @owned replica sets which is neither the template
+// of the violating pod replica set @podRS nor recorded as tampered before.
+// Returns the revision of @podRS and the target revision.
+func rollbackTemplate(deployment *appsv1.Deployment, owned []appsv1.ReplicaSet, podRS *appsv1.ReplicaSet) (string, string, error) {
+	from := podRS.Annotations[RevisionAnnotation]
+	tampered := tamperedTemplates(deployment.Annotations[TamperedTemplatesAnnotation])
+	if hash := podRS.Labels[appsv1.DefaultDeploymentUniqueLabelKey]; hash != "" {
+		tampered[hash] = struct{}{}
+	}
+
+	target := previousRevision(owned, tampered)
+	if target == nil {
+		return from, "", fmt.Errorf("deployment %s revision %s: %w", deployment.Name, from, ErrNoHealthyRevision)
+	}
+	to := target.Annotations[RevisionAnnotation]
+
+	template := target.Spec.Template.DeepCopy()
+	delete(template.Labels, appsv1.DefaultDeploymentUniqueLabelKey)
+	deployment.Spec.Template = *template
+	if deployment.Annotations == nil {
+		deployment.Annotations = make(map[string]string)
+	}
+	deployment.Annotations[RollbackFromAnnotation] = from
+	deployment.Annotations[RollbackToAnnotation] = to
+	deployment.Annotations[RollbackTimeAnnotation] = time.Now().UTC().Format(time.RFC3339)
+	deployment.Annotations[TamperedTemplatesAnnotation] = formatTamperedTemplates(tampered)
+	return from, to, nil
+}
+
+// previousRevision returns the replica set with the highest revision whose pod
+// template is not @tampered.
+func previousRevision(rsList []appsv1.ReplicaSet, tampered map[string]struct{}) *appsv1.ReplicaSet {
+	var (
+		target   *appsv1.ReplicaSet
+		revision int64 = -1
+	)
+	for i := range rsList {
+		rs := &rsList[i]
+		if _, ok := tampered[rs.Labels[appsv1.DefaultDeploymentUniqueLabelKey]]; ok {
+			continue
+		}
+		v, err := strconv.ParseInt(rs.Annotations[RevisionAnnotation], 10, 64)
+		if err != nil {
+			continue
+		}
+		if v > revision {
+			target, revision = rs, v
+		}
+	}
+	return target
+}
+
+func tamperedTemplates(s string) map[string]struct{} {
+	res := make(map[string]struct{})
+	for _, v := range strings.Split(s, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			res[v] = struct{}{}
+		}
+	}
+	return res
+}
+
+func formatTamperedTemplates(m map[string]struct{}) string {
+	res := make([]string, 0, len(m))
+	for k := range m {
+		res = append(res, k)
+	}
+	sort.Strings(res)
+	return strings.Join(res, ",")
+}
@@ -0,0 +1,75 @@
+package k8s
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestPreviousRevision(t *testing.T) {
+	rs := func(hash, revision string) appsv1.ReplicaSet {
+		return appsv1.ReplicaSet{ObjectMeta: metav1.ObjectMeta{
+			Name:        "app-" + hash,
+			Labels:      map[string]string{appsv1.DefaultDeploymentUniqueLabelKey: hash},
+			Annotations: map[string]string{RevisionAnnotation: revision},
+		}}
+	}
+	rsList := []appsv1.ReplicaSet{rs("a", "1"), rs("c", "3"), rs("b", "2"), rs("x", "")}
+
+	got := previousRevision(rsList, tamperedTemplates("c"))
+	assert.Equal(t, "app-b", got.Name)
+
+	got = previousRevision(rsList, tamperedTemplates("c, b"))
+	assert.Equal(t, "app-a", got.Name)
+
+	got = previousRevision(rsList, tamperedTemplates("a,b,c"))
+	assert.Nil(t, got)
+}
+
+func TestTamperedTemplates(t *testing.T) {
+	m := tamperedTemplates(" b,a,,c ")
+	assert.Len(t, m, 3)
+	assert.Equal(t, "a,b,c", formatTamperedTemplates(m))
+	assert.Empty(t, tamperedTemplates(""))
+}
+
+func TestRollbackTemplate(t *testing.T) {
+	rs := func(hash, revision string) appsv1.ReplicaSet {
+		return appsv1.ReplicaSet{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "app-" + hash,
+				Labels:      map[string]string{appsv1.DefaultDeploymentUniqueLabelKey: hash},
+				Annotations: map[string]string{RevisionAnnotation: revision},
+			},
+			Spec: appsv1.ReplicaSetSpec{Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{
+					"app": "app", appsv1.DefaultDeploymentUniqueLabelKey: hash,
+				}},
+				Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "app", Image: "app:" + revision}}},
+			}},
+		}
+	}
+	owned := []appsv1.ReplicaSet{rs("a", "1"), rs("b", "2"), rs("c", "3")}
+
+	// the rollout to the revision 3 is in progress, a pod of the revision 2
+	// violates: its template is marked, not the current one
+	deployment := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{
+		Name:        "app",
+		Annotations: map[string]string{RevisionAnnotation: "3", TamperedTemplatesAnnotation: "a"},
+	}}
+	from, to, err := rollbackTemplate(deployment, owned, &owned[1])
+	assert.NoError(t, err)
+	assert.Equal(t, "2", from)
+	assert.Equal(t, "3", to)
+	assert.Equal(t, "app:3", deployment.Spec.Template.Spec.Containers[0].Image)
+	assert.Equal(t, map[string]string{"app": "app"}, deployment.Spec.Template.Labels)
+	assert.Equal(t, "a,b", deployment.Annotations[TamperedTemplatesAnnotation])
+	assert.Equal(t, "2", deployment.Annotations[RollbackFromAnnotation])
+	assert.Equal(t, "3", deployment.Annotations[RollbackToAnnotation])
+
+	_, _, err = rollbackTemplate(deployment, owned, &owned[2])
+	assert.ErrorIs(t, err, ErrNoHealthyRevision)
+}
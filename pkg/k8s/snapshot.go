@@ -0,0 +1,85 @@
+package k8s
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// SnapshotGVR is the resource of the Snapshot CRD served by the
+// snapshot-controller
+var SnapshotGVR = schema.GroupVersionResource{Group: "integrity.snapshot", Version: "v1", Resource: "snapshots"}
+
+const (
+	// snapshotFinalizer makes the snapshot-controller remove the snapshot from
+	// MinIO along with the CR
+	snapshotFinalizer = "controller.snapshot/finalizer"
+
+	AnnotationLearnedFrom = "integrity-monitor.scnsoft.com/learned-from"
+	AnnotationLearnedAt   = "integrity-monitor.scnsoft.com/learned-at"
+)
+
+var invalidNameChars = regexp.MustCompile(`[^a-z0-9.-]+`)
+
+// LearnedSnapshotName returns the name of the Snapshot CR learned for the
+// @image hashed with the @alg algorithm, e.g. learned-nginx-1.25-sha256.
+func LearnedSnapshotName(image, alg string) string {
+	name := "learned-" + strings.ReplaceAll(image, ":", "-") + "-" + alg
+	name = strings.Trim(invalidNameChars.ReplaceAllString(strings.ToLower(name), "-"), "-.")
+	if len(name) > 253 {
+		name = strings.TrimRight(name[:253], "-.")
+	}
+	return name
+}
+
+// CreateSnapshot creates the Snapshot CR in the pod namespace with the
+// @hashes of the @image calculated with the @alg algorithm, the
+// snapshot-controller uploads it to MinIO. Returns the name of the created CR,
+// an existing CR is not overwritten.
+func (ks *KubeClient) CreateSnapshot(ctx context.Context, image, alg string, hashes []byte) (string, error) {
+	name := LearnedSnapshotName(image, alg)
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": SnapshotGVR.GroupVersion().String(),
+		"kind":       "Snapshot",
+		"metadata": map[string]interface{}{
+			"name":       name,
+			"namespace":  kubeData.PodNamespace,
+			"finalizers": []interface{}{snapshotFinalizer},
+			"labels": map[string]interface{}{
+				"app.kubernetes.io/name":       "snapshot",
+				"app.kubernetes.io/created-by": "integrity-monitor",
+			},
+			"annotations": map[string]interface{}{
+				AnnotationLearnedFrom: kubeData.PodName,
+				AnnotationLearnedAt:   time.Now().UTC().Format(time.RFC3339),
+			},
+		},
+		"spec": map[string]interface{}{
+			"image":     image,
+			"hashes":    base64.StdEncoding.EncodeToString(hashes),
+			"algorithm": strings.ToLower(alg),
+		},
+	}}
+
+	_, err := ks.dynamic.Resource(SnapshotGVR).Namespace(kubeData.PodNamespace).Create(
+		ctx, obj, metav1.CreateOptions{})
+	if apierrors.IsAlreadyExists(err) {
+		ks.logger.Printf("### 👎 Warning: Snapshot %v already exists, it is not overwritten", name)
+		return name, nil
+	}
+	if err != nil {
+		ks.logger.Printf("### 👎 Warning: Failed to create snapshot %v: %v", name, err)
+		return "", fmt.Errorf("failed create snapshot %s: %w", name, err)
+	}
+
+	ks.logger.Printf("### ✅ Snapshot %v was created", name)
+	return name, nil
+}
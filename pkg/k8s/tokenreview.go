@@ -0,0 +1,27 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// AuthenticateToken verifies the bearer @token with the TokenReview API and
+// returns the name of the authenticated user
+func (ks *KubeClient) AuthenticateToken(ctx context.Context, token string) (string, error) {
+	review, err := ks.clientset.AuthenticationV1().TokenReviews().Create(ctx, &authenticationv1.TokenReview{
+		Spec: authenticationv1.TokenReviewSpec{Token: token},
+	}, metav1.CreateOptions{})
+	if err != nil {
+		return "", err
+	}
+	if !review.Status.Authenticated {
+		if review.Status.Error != "" {
+			return "", fmt.Errorf("token is not authenticated: %s", review.Status.Error)
+		}
+		return "", fmt.Errorf("token is not authenticated")
+	}
+	return review.Status.User.Username, nil
+}
@@ -0,0 +1,115 @@
+// Package metrics defines the Prometheus metrics of the monitor and serves
+// them on the /metrics endpoint.
+package metrics
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/sirupsen/logrus"
+)
+
+const namespace = "integrity"
+
+// Results of the operations
+const (
+	ResultSuccess = "success"
+	ResultFailure = "failure"
+)
+
+var (
+	ScanDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "scan_duration_seconds",
+		Help:      "Duration of the full scans of the process.",
+		Buckets:   prometheus.ExponentialBuckets(0.1, 2, 12),
+	}, []string{"process", "status"})
+
+	FilesHashed = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "files_hashed_total",
+		Help:      "Number of the files hashed by the workers.",
+	})
+
+	BytesHashed = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "bytes_hashed_total",
+		Help:      "Number of the bytes read by the workers to hash the files.",
+	})
+
+	StatCacheHits = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "stat_cache_hits_total",
+		Help:      "Number of the files whose hashes were taken from the stat cache.",
+	})
+
+	Violations = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "violations_total",
+		Help:      "Number of the integrity violations found by type.",
+	}, []string{"process", "type"})
+
+	AlertsSent = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "alerts_sent_total",
+		Help:      "Number of the alerts sent by the alert sender.",
+	}, []string{"sender", "result"})
+
+	ResponseActions = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "response_actions_total",
+		Help:      "Number of the response actions performed, audited ones are not performed.",
+	}, []string{"process", "action", "result"})
+
+	PodRestarts = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "pod_restarts_total",
+		Help:      "Number of the pod restarts triggered.",
+	})
+
+	MinIODuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "minio_request_duration_seconds",
+		Help:      "Latency of the MinIO requests by operation.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"operation", "result"})
+)
+
+// Result returns the result label for the @err.
+func Result(err error) string {
+	if err != nil {
+		return ResultFailure
+	}
+	return ResultSuccess
+}
+
+// ObserveMinIO records the latency of the MinIO @operation started at @start.
+func ObserveMinIO(operation string, start time.Time, err error) {
+	MinIODuration.WithLabelValues(operation, Result(err)).Observe(time.Since(start).Seconds())
+}
+
+// Serve serves the /metrics endpoint on the @addr until the @ctx is done.
+func Serve(ctx context.Context, log *logrus.Logger, addr string) error {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.Handler())
+	srv := &http.Server{Addr: addr, Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := srv.Shutdown(shutdownCtx); err != nil {
+			log.WithError(err).Error("failed shutdown metrics server")
+		}
+	}()
+
+	log.WithField("addr", addr).Info("metrics server started")
+	if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+package metrics_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ScienceSoft-Inc/integrity-sum/internal/worker"
+	"github.com/ScienceSoft-Inc/integrity-sum/pkg/alerts"
+	"github.com/ScienceSoft-Inc/integrity-sum/pkg/metrics"
+)
+
+type fakeSender struct {
+	err error
+}
+
+func (s fakeSender) Name() string { return "fake" }
+
+func (s fakeSender) Send(alerts.Alert) error { return s.err }
+
+// scan hashes the @files twice with the stat cache like two scan cycles do
+func scan(files ...string) {
+	cache := worker.NewStatCache()
+	for i := 0; i < 2; i++ {
+		cache.Begin(0)
+		fileNameC := make(chan string, len(files))
+		for _, f := range files {
+			fileNameC <- f
+		}
+		close(fileNameC)
+		w := worker.NewWorker(context.Background(), "sha256", logrus.New(), worker.WithStatCache(cache))
+		for range worker.WorkersPool(1, fileNameC, w) {
+		}
+	}
+}
+
+func TestMetrics(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "app.conf")
+	require.NoError(t, os.WriteFile(file, []byte("data"), 0o644))
+
+	filesHashed := testutil.ToFloat64(metrics.FilesHashed)
+	bytesHashed := testutil.ToFloat64(metrics.BytesHashed)
+	cacheHits := testutil.ToFloat64(metrics.StatCacheHits)
+	scan(file)
+	assert.Equal(t, filesHashed+1, testutil.ToFloat64(metrics.FilesHashed))
+	assert.Equal(t, bytesHashed+4, testutil.ToFloat64(metrics.BytesHashed))
+	assert.Equal(t, cacheHits+1, testutil.ToFloat64(metrics.StatCacheHits))
+
+	// the scan has found a violation, it is alerted and responded
+	metrics.ScanDuration.WithLabelValues("nginx", "violation").Observe(1.5)
+	metrics.Violations.WithLabelValues("nginx", "file content mismatch").Inc()
+	metrics.ResponseActions.WithLabelValues("nginx", "restart", metrics.Result(nil)).Inc()
+	metrics.PodRestarts.Inc()
+	metrics.ObserveMinIO("load", time.Now(), nil)
+	alerts.Register(fakeSender{})
+	alerts.Register(fakeSender{err: errors.New("unavailable")})
+	assert.Error(t, alerts.Send(context.Background(), alerts.New("Restart deployment", "file content mismatch", "etc/app.conf", "nginx")))
+
+	assert.Equal(t, 1.0, testutil.ToFloat64(metrics.Violations.WithLabelValues("nginx", "file content mismatch")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(metrics.AlertsSent.WithLabelValues("fake", metrics.ResultSuccess)))
+	assert.Equal(t, 1.0, testutil.ToFloat64(metrics.AlertsSent.WithLabelValues("fake", metrics.ResultFailure)))
+	assert.Equal(t, 1.0, testutil.ToFloat64(metrics.ResponseActions.WithLabelValues("nginx", "restart", metrics.ResultSuccess)))
+
+	// the metrics are exposed with the documented names and labels
+	families, err := prometheus.DefaultGatherer.Gather()
+	require.NoError(t, err)
+	labels := make(map[string][]string)
+	for _, f := range families {
+		var names []string
+		for _, l := range f.GetMetric()[0].GetLabel() {
+			names = append(names, l.GetName())
+		}
+		sort.Strings(names)
+		labels[f.GetName()] = names
+	}
+	for name, want := range map[string][]string{
+		"integrity_scan_duration_seconds":          {"process", "status"},
+		"integrity_files_hashed_total":             nil,
+		"integrity_bytes_hashed_total":             nil,
+		"integrity_stat_cache_hits_total":          nil,
+		"integrity_violations_total":               {"process", "type"},
+		"integrity_alerts_sent_total":              {"result", "sender"},
+		"integrity_response_actions_total":         {"action", "process", "result"},
+		"integrity_pod_restarts_total":             nil,
+		"integrity_minio_request_duration_seconds": {"operation", "result"},
+	} {
+		got, ok := labels[name]
+		if assert.True(t, ok, name) {
+			assert.Equal(t, want, got, name)
+		}
+	}
+	assert.Equal(t, 1, testutil.CollectAndCount(metrics.ScanDuration, "integrity_scan_duration_seconds"))
+	assert.Equal(t, 1, testutil.CollectAndCount(metrics.MinIODuration, "integrity_minio_request_duration_seconds"))
+}
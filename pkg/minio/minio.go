@@ -9,12 +9,15 @@ import (
 	"path/filepath"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/minio/minio-go/v7"
 	"github.com/minio/minio-go/v7/pkg/credentials"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
+
+	"github.com/ScienceSoft-Inc/integrity-sum/pkg/metrics"
 )
 
 // Error messages
@@ -99,13 +102,42 @@ func NewStorage(log *logrus.Logger) (*Storage, error) {
 
 // Save stores @data into the @bucketName with the given @objectName
 func (s *Storage) Save(ctx context.Context, bucketName, objectName string, data []byte) error {
-	r := bytes.NewReader(data)
+	return s.SaveReader(ctx, bucketName, objectName, bytes.NewReader(data), int64(len(data)))
+}
+
+// SaveReader streams @size bytes of the @r reader into the @bucketName with
+// the given @objectName
+func (s *Storage) SaveReader(ctx context.Context, bucketName, objectName string, r io.Reader, size int64) (err error) {
+	start := time.Now()
+	defer func() { metrics.ObserveMinIO("save", start, err) }()
 	info, err := s.client.PutObject(
 		ctx,
 		bucketName,
 		objectName,
 		r,
-		r.Size(),
+		size,
+		minio.PutObjectOptions{ContentType: "application/octet-stream"},
+	)
+	if err != nil {
+		return fmt.Errorf(MsgFailedUpload, err)
+	}
+	s.log.WithFields(logrus.Fields{
+		"objectName": objectName,
+		"size":       info.Size,
+	}).Debug("uploaded successfully")
+	return nil
+}
+
+// SaveFile uploads the @filePath file into the @bucketName with the given
+// @objectName
+func (s *Storage) SaveFile(ctx context.Context, bucketName, objectName, filePath string) (err error) {
+	start := time.Now()
+	defer func() { metrics.ObserveMinIO("save", start, err) }()
+	info, err := s.client.FPutObject(
+		ctx,
+		bucketName,
+		objectName,
+		filePath,
 		minio.PutObjectOptions{ContentType: "application/octet-stream"},
 	)
 	if err != nil {
@@ -119,7 +151,9 @@ func (s *Storage) Save(ctx context.Context, bucketName, objectName string, data
 }
 
 // Load loads and returns data from the @bucketName for the @objectName
-func (s *Storage) Load(ctx context.Context, bucketName, objectName string) ([]byte, error) {
+func (s *Storage) Load(ctx context.Context, bucketName, objectName string) (data []byte, err error) {
+	start := time.Now()
+	defer func() { metrics.ObserveMinIO("load", start, err) }()
 	opts := minio.GetObjectOptions{}
 	opts.Set("Cache-Control", "no-cache")
 	r, err := s.client.GetObject(
@@ -144,6 +178,39 @@ func (s *Storage) Load(ctx context.Context, bucketName, objectName string) ([]by
 	return io.ReadAll(r)
 }
 
+// Open returns the reader of the @objectName from the @bucketName, the caller
+// closes it
+func (s *Storage) Open(ctx context.Context, bucketName, objectName string) (r io.ReadCloser, err error) {
+	start := time.Now()
+	defer func() { metrics.ObserveMinIO("load", start, err) }()
+	opts := minio.GetObjectOptions{}
+	opts.Set("Cache-Control", "no-cache")
+	obj, err := s.client.GetObject(ctx, bucketName, objectName, opts)
+	if err != nil {
+		return nil, fmt.Errorf(MsgFailedLoad, err)
+	}
+	// GetObject does not fail on a missing object, Stat does
+	if _, err := obj.Stat(); err != nil {
+		obj.Close()
+		return nil, fmt.Errorf(MsgFailedGetInfo, err)
+	}
+	return obj, nil
+}
+
+// Exists reports whether the @objectName exists in the @bucketName
+func (s *Storage) Exists(ctx context.Context, bucketName, objectName string) (exists bool, err error) {
+	start := time.Now()
+	defer func() { metrics.ObserveMinIO("stat", start, err) }()
+	_, err = s.client.StatObject(ctx, bucketName, objectName, minio.StatObjectOptions{})
+	if err != nil {
+		if minio.ToErrorResponse(err).Code == "NoSuchKey" {
+			return false, nil
+		}
+		return false, fmt.Errorf(MsgFailedGetInfo, err)
+	}
+	return true, nil
+}
+
 // Remove removes the @objName from the @bucketName
 func (s *Storage) Remove(ctx context.Context, bucketName string, objName string) error {
 	err := s.client.RemoveObject(ctx, bucketName, objName, minio.RemoveObjectOptions{})
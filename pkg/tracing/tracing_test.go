@@ -0,0 +1,44 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+func TestLogHook(t *testing.T) {
+	var buf bytes.Buffer
+	log := logrus.New()
+	log.SetOutput(&buf)
+	log.AddHook(LogHook{})
+
+	log.WithContext(context.Background()).Info("untraced")
+	assert.NotContains(t, buf.String(), "trace_id")
+	assert.Empty(t, TraceID(context.Background()))
+
+	tp := sdktrace.NewTracerProvider()
+	defer func() { _ = tp.Shutdown(context.Background()) }()
+	ctx, span := tp.Tracer("test").Start(context.Background(), "scan")
+	defer span.End()
+
+	buf.Reset()
+	log.WithContext(ctx).Info("traced")
+	traceID := span.SpanContext().TraceID().String()
+	assert.Equal(t, traceID, TraceID(ctx))
+	assert.Contains(t, buf.String(), "trace_id="+traceID)
+	assert.Contains(t, buf.String(), "span_id="+span.SpanContext().SpanID().String())
+}
+
+func TestInitDisabled(t *testing.T) {
+	shutdown, err := Init(context.Background(), Config{})
+	assert.NoError(t, err)
+	assert.NoError(t, shutdown(context.Background()))
+
+	_, span := Start(context.Background(), "noop")
+	assert.False(t, span.SpanContext().IsValid())
+	End(span, nil)
+}
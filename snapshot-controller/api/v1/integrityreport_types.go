@@ -0,0 +1,92 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Scan results of the IntegrityReport
+const (
+	ScanResultOK        = "ok"
+	ScanResultViolation = "violation"
+	ScanResultError     = "error"
+)
+
+// IntegrityReportSpec identifies the scanned process of the pod and the
+// snapshot it is verified against
+type IntegrityReportSpec struct {
+	PodName   string `json:"podName"`
+	Process   string `json:"process"`
+	Image     string `json:"image,omitempty"`
+	Algorithm string `json:"algorithm,omitempty"`
+}
+
+// Violation is an integrity violation found by the scan
+type Violation struct {
+	Reason   string `json:"reason"`
+	Path     string `json:"path,omitempty"`
+	Expected string `json:"expected,omitempty"`
+	Actual   string `json:"actual,omitempty"`
+}
+
+// IntegrityReportStatus holds the outcome of the last scan of the process
+type IntegrityReportStatus struct {
+	// Result is ok, violation or error
+	Result   string       `json:"result,omitempty"`
+	LastScan *metav1.Time `json:"lastScan,omitempty"`
+	Audit    bool         `json:"audit,omitempty"`
+	Error    string       `json:"error,omitempty"`
+	// Scans counts the scans reported by the sidecar by result
+	Scans map[string]int64 `json:"scans,omitempty"`
+	// ViolationCount is the number of the violations found by the last scan,
+	// Violations may be truncated
+	ViolationCount int         `json:"violationCount,omitempty"`
+	Violations     []Violation `json:"violations,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:resource:shortName=ir
+//+kubebuilder:printcolumn:name="Pod",type=string,JSONPath=`.spec.podName`
+//+kubebuilder:printcolumn:name="Process",type=string,JSONPath=`.spec.process`
+//+kubebuilder:printcolumn:name="Result",type=string,JSONPath=`.status.result`
+//+kubebuilder:printcolumn:name="Violations",type=integer,JSONPath=`.status.violationCount`
+//+kubebuilder:printcolumn:name="Last scan",type=date,JSONPath=`.status.lastScan`
+
+// IntegrityReport is the Schema for the integrityreports API, it is owned by
+// the pod and updated by the monitor sidecar after each scan
+type IntegrityReport struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   IntegrityReportSpec   `json:"spec,omitempty"`
+	Status IntegrityReportStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// IntegrityReportList contains a list of IntegrityReport
+type IntegrityReportList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []IntegrityReport `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&IntegrityReport{}, &IntegrityReportList{})
+}
@@ -34,6 +34,29 @@ type SnapshotSpec struct {
 type SnapshotStatus struct {
 	IsUploaded  bool   `json:"isUploaded,omitempty"`
 	ControlHash string `json:"controlHash,omitempty"`
+	// Pods lists the failing pod processes verified against the snapshot,
+	// aggregated from their IntegrityReports. The list is limited to
+	// MaxSnapshotPods since the object size is limited by etcd, the counts
+	// include all the pod processes.
+	//+kubebuilder:validation:MaxItems=50
+	Pods []SnapshotPodStatus `json:"pods,omitempty"`
+	// Passing and Failing count the pod processes by the result of their last
+	// scan, the failed scans are counted as failing
+	Passing int `json:"passing,omitempty"`
+	Failing int `json:"failing,omitempty"`
+}
+
+// MaxSnapshotPods limits the pod processes listed in the SnapshotStatus
+const MaxSnapshotPods = 50
+
+// SnapshotPodStatus is the last scan result of a pod process using the
+// snapshot
+type SnapshotPodStatus struct {
+	Pod            string       `json:"pod"`
+	Process        string       `json:"process"`
+	Result         string       `json:"result,omitempty"`
+	LastScan       *metav1.Time `json:"lastScan,omitempty"`
+	ViolationCount int          `json:"violationCount,omitempty"`
 }
 
 //+kubebuilder:object:root=true
@@ -41,6 +64,8 @@ type SnapshotStatus struct {
 //+kubebuilder:printcolumn:name="Image",type=string,JSONPath=`.spec.image`
 //+kubebuilder:printcolumn:name="Uploaded",type=string,JSONPath=`.status.isUploaded`
 //+kubebuilder:printcolumn:name="hash",type=string,JSONPath=`.status.controlHash`
+//+kubebuilder:printcolumn:name="Passing",type=integer,JSONPath=`.status.passing`
+//+kubebuilder:printcolumn:name="Failing",type=integer,JSONPath=`.status.failing`
 
 // Snapshot is the Schema for the snapshots API
 type Snapshot struct {
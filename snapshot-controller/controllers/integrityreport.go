@@ -0,0 +1,123 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	integrityv1 "github.com/ScienceSoft-Inc/integrity-sum/snapshot-controller/api/v1"
+)
+
+//+kubebuilder:rbac:groups=integrity.snapshot,resources=integrityreports,verbs=get;list;watch
+
+// ..aggregates the IntegrityReports of the pods verified against the @snapshot
+// onto its status, returns true if the status has changed
+func (r *SnapshotReconciler) aggregateReports(ctx context.Context, snapshot *integrityv1.Snapshot) (bool, error) {
+	var reports integrityv1.IntegrityReportList
+	if err := r.List(ctx, &reports, client.InNamespace(snapshot.Namespace)); err != nil {
+		return false, err
+	}
+
+	pods, passing, failing := podStatuses(snapshot, reports.Items)
+
+	if equality.Semantic.DeepEqual(pods, snapshot.Status.Pods) &&
+		passing == snapshot.Status.Passing && failing == snapshot.Status.Failing {
+		return false, nil
+	}
+	snapshot.Status.Pods, snapshot.Status.Passing, snapshot.Status.Failing = pods, passing, failing
+	return true, nil
+}
+
+// ..returns the last scan results of the failing pod processes of the
+// @reports matching the @snapshot sorted by the pod and the process names, at
+// most MaxSnapshotPods of them, and the counts of the passing and the failing
+// pod processes
+func podStatuses(snapshot *integrityv1.Snapshot, reports []integrityv1.IntegrityReport) (
+	pods []integrityv1.SnapshotPodStatus, passing, failing int) {
+	for i := range reports {
+		report := &reports[i]
+		if !matchesSnapshot(report, snapshot) || report.DeletionTimestamp != nil {
+			continue
+		}
+		switch report.Status.Result {
+		case integrityv1.ScanResultOK:
+			passing++
+			continue
+		case integrityv1.ScanResultViolation, integrityv1.ScanResultError:
+			failing++
+		default:
+			// not scanned yet
+			continue
+		}
+		pods = append(pods, integrityv1.SnapshotPodStatus{
+			Pod:            report.Spec.PodName,
+			Process:        report.Spec.Process,
+			Result:         report.Status.Result,
+			LastScan:       report.Status.LastScan,
+			ViolationCount: report.Status.ViolationCount,
+		})
+	}
+	sort.Slice(pods, func(i, j int) bool {
+		if pods[i].Pod != pods[j].Pod {
+			return pods[i].Pod < pods[j].Pod
+		}
+		return pods[i].Process < pods[j].Process
+	})
+	if len(pods) > integrityv1.MaxSnapshotPods {
+		pods = pods[:integrityv1.MaxSnapshotPods]
+	}
+	return pods, passing, failing
+}
+
+// ..reports whether the @report process is verified against the @snapshot
+func matchesSnapshot(report *integrityv1.IntegrityReport, snapshot *integrityv1.Snapshot) bool {
+	return report.Namespace == snapshot.Namespace &&
+		report.Spec.Image == snapshot.Spec.Image &&
+		strings.EqualFold(report.Spec.Algorithm, snapshot.Spec.Algorithm)
+}
+
+// ..maps the IntegrityReport @obj to the requests for the matching snapshots
+func (r *SnapshotReconciler) snapshotsForReport(obj client.Object) []reconcile.Request {
+	report, ok := obj.(*integrityv1.IntegrityReport)
+	if !ok {
+		return nil
+	}
+
+	var snapshots integrityv1.SnapshotList
+	if err := r.List(context.Background(), &snapshots, client.InNamespace(report.Namespace)); err != nil {
+		r.Log.Error(err, "unable to list snapshots", "integrityReport", report.Name)
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for i := range snapshots.Items {
+		snapshot := &snapshots.Items[i]
+		if matchesSnapshot(report, snapshot) {
+			requests = append(requests, reconcile.Request{
+				NamespacedName: types.NamespacedName{Namespace: snapshot.Namespace, Name: snapshot.Name},
+			})
+		}
+	}
+	return requests
+}
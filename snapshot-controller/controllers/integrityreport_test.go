@@ -0,0 +1,69 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"fmt"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	integrityv1 "github.com/ScienceSoft-Inc/integrity-sum/snapshot-controller/api/v1"
+)
+
+func TestPodStatuses(t *testing.T) {
+	g := NewWithT(t)
+
+	snapshot := &integrityv1.Snapshot{
+		ObjectMeta: metav1.ObjectMeta{Name: "nginx", Namespace: "default"},
+		Spec:       integrityv1.SnapshotSpec{Image: "nginx:1.25", Algorithm: "sha256"},
+	}
+	report := func(ns, pod, image, alg, result string) integrityv1.IntegrityReport {
+		return integrityv1.IntegrityReport{
+			ObjectMeta: metav1.ObjectMeta{Name: pod + "-nginx", Namespace: ns},
+			Spec:       integrityv1.IntegrityReportSpec{PodName: pod, Process: "nginx", Image: image, Algorithm: alg},
+			Status:     integrityv1.IntegrityReportStatus{Result: result},
+		}
+	}
+
+	pods, passing, failing := podStatuses(snapshot, []integrityv1.IntegrityReport{
+		report("default", "web-b", "nginx:1.25", "SHA256", integrityv1.ScanResultViolation),
+		report("default", "web-a", "nginx:1.25", "sha256", integrityv1.ScanResultOK),
+		report("default", "web-f", "nginx:1.25", "sha256", integrityv1.ScanResultError),
+		report("default", "web-g", "nginx:1.25", "sha256", ""),
+		report("default", "web-c", "nginx:1.24", "sha256", integrityv1.ScanResultOK),
+		report("default", "web-d", "nginx:1.25", "md5", integrityv1.ScanResultOK),
+		report("other", "web-e", "nginx:1.25", "sha256", integrityv1.ScanResultOK),
+	})
+	g.Expect(pods).To(Equal([]integrityv1.SnapshotPodStatus{
+		{Pod: "web-b", Process: "nginx", Result: integrityv1.ScanResultViolation},
+		{Pod: "web-f", Process: "nginx", Result: integrityv1.ScanResultError},
+	}))
+	g.Expect(passing).To(Equal(1))
+	g.Expect(failing).To(Equal(2))
+
+	// the failing pods are limited, the counts are not
+	var reports []integrityv1.IntegrityReport
+	for i := 0; i < integrityv1.MaxSnapshotPods+10; i++ {
+		reports = append(reports, report("default", fmt.Sprintf("web-%03d", i), "nginx:1.25", "sha256", integrityv1.ScanResultViolation))
+	}
+	pods, _, failing = podStatuses(snapshot, reports)
+	g.Expect(pods).To(HaveLen(integrityv1.MaxSnapshotPods))
+	g.Expect(pods[0].Pod).To(Equal("web-000"))
+	g.Expect(failing).To(Equal(integrityv1.MaxSnapshotPods + 10))
+}
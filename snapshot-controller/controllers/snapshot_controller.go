@@ -33,7 +33,9 @@ import (
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	mstorage "github.com/ScienceSoft-Inc/integrity-sum/pkg/minio"
 	integrityv1 "github.com/ScienceSoft-Inc/integrity-sum/snapshot-controller/api/v1"
@@ -120,6 +122,18 @@ func (r *SnapshotReconciler) Reconcile(ctx context.Context, req ctrl.Request) (c
 		}
 	}
 
+	// aggregate the scan results of the pods using the snapshot
+	changed, err := r.aggregateReports(ctx, &snapshot)
+	if err != nil {
+		r.Log.Error(err, "unable to aggregate integrity reports", "snapshot", snapshot.Name)
+		return ctrl.Result{}, err
+	}
+	if changed {
+		if err = updateStatus(ctx, &snapshot); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
 	return ctrl.Result{}, nil
 }
 
@@ -227,5 +241,7 @@ func md5hash(s string) string {
 func (r *SnapshotReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&integrityv1.Snapshot{}).
+		Watches(&source.Kind{Type: &integrityv1.IntegrityReport{}},
+			handler.EnqueueRequestsFromMapFunc(r.snapshotsForReport)).
 		Complete(r)
 }
@@ -34,6 +34,7 @@ import (
 
 	integrityv1 "github.com/ScienceSoft-Inc/integrity-sum/snapshot-controller/api/v1"
 	"github.com/ScienceSoft-Inc/integrity-sum/snapshot-controller/controllers"
+	"github.com/ScienceSoft-Inc/integrity-sum/snapshot-controller/webhooks"
 
 	//+kubebuilder:scaffold:imports
 	_ "github.com/ScienceSoft-Inc/integrity-sum/pkg/minio"
@@ -57,6 +58,8 @@ func main() {
 		enableLeaderElection bool
 		probeAddr            string
 		minioHost            string
+		injectorConfig       string
+		snapshotValidation   string
 		verboseLevel         int
 	)
 	flag.StringVar(&metricsAddr, "metrics-bind-address", ":8080", "The address the metric endpoint binds to.")
@@ -65,6 +68,12 @@ func main() {
 		"Enable leader election for controller manager. "+
 			"Enabling this will ensure there is only one active controller manager.")
 	flag.StringVar(&minioHost, "minio-host", "minio.minio.svc.cluster.local:9000", "MinIO host")
+	flag.StringVar(&injectorConfig, "injector-config", "",
+		"Path to the integrity monitor sidecar configuration. "+
+			"The sidecar injector webhook is enabled if it is set.")
+	flag.StringVar(&snapshotValidation, "snapshot-validation", webhooks.ValidationOff,
+		"Validation of the monitored pods without an uploaded snapshot: "+
+			"off, warn (admit with a warning) or deny (reject).")
 	flag.IntVar(&verboseLevel, "v", 0, "verbose level")
 	opts := zap.Options{
 		Development: true,
@@ -106,6 +115,30 @@ func main() {
 		setupLog.Error(err, "unable to create controller", "controller", "Snapshot")
 		os.Exit(1)
 	}
+	if injectorConfig != "" {
+		cfg, err := webhooks.LoadInjectorConfig(injectorConfig)
+		if err != nil {
+			setupLog.Error(err, "unable to load injector config")
+			os.Exit(1)
+		}
+		if err = (&webhooks.PodInjector{
+			Config: cfg,
+			Log:    ctrl.Log.WithName("webhooks").WithName("PodInjector").V(verboseLevel),
+		}).SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "PodInjector")
+			os.Exit(1)
+		}
+	}
+	if snapshotValidation != webhooks.ValidationOff {
+		if err = (&webhooks.SnapshotValidator{
+			Client: mgr.GetClient(),
+			Mode:   snapshotValidation,
+			Log:    ctrl.Log.WithName("webhooks").WithName("SnapshotValidator").V(verboseLevel),
+		}).SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "SnapshotValidator")
+			os.Exit(1)
+		}
+	}
 	//+kubebuilder:scaffold:builder
 
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
@@ -0,0 +1,121 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhooks
+
+import (
+	"fmt"
+	"os"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/yaml"
+)
+
+// InjectorConfig is the cluster-wide configuration of the injected integrity
+// monitor sidecar
+type InjectorConfig struct {
+	// Image of the integrity monitor sidecar
+	Image string `json:"image"`
+	// ImagePullPolicy of the sidecar image, IfNotPresent by default
+	ImagePullPolicy corev1.PullPolicy `json:"imagePullPolicy,omitempty"`
+	// ContainerName of the sidecar, integrity by default
+	ContainerName string `json:"containerName,omitempty"`
+	// ServiceAccountName the injected pods run under, integrity-monitor by
+	// default. It must be bound to the crd-sidecar-role in the pod namespace.
+	ServiceAccountName string `json:"serviceAccountName,omitempty"`
+	// Args are appended to the arguments of every sidecar, e.g. --verbose=info
+	Args []string `json:"args,omitempty"`
+	// Resources of the sidecar container
+	Resources corev1.ResourceRequirements `json:"resources,omitempty"`
+
+	MinIO  MinIOConfig  `json:"minio,omitempty"`
+	Syslog SyslogConfig `json:"syslog,omitempty"`
+	Splunk SplunkConfig `json:"splunk,omitempty"`
+}
+
+// MinIOConfig is the MinIO connection of the sidecar. The credentials are
+// read from the SecretName secret in the namespace of the pod.
+type MinIOConfig struct {
+	Enabled     bool   `json:"enabled,omitempty"`
+	Host        string `json:"host,omitempty"`
+	SecretName  string `json:"secretName,omitempty"`
+	UserKey     string `json:"userKey,omitempty"`
+	PasswordKey string `json:"passwordKey,omitempty"`
+}
+
+// SyslogConfig is the syslog alert settings of the sidecar
+type SyslogConfig struct {
+	Enabled bool   `json:"enabled,omitempty"`
+	Host    string `json:"host,omitempty"`
+	Port    string `json:"port,omitempty"`
+	Proto   string `json:"proto,omitempty"`
+}
+
+// SplunkConfig is the Splunk alert settings of the sidecar. The HEC token is
+// read from the SecretName secret in the namespace of the pod.
+type SplunkConfig struct {
+	Enabled            bool   `json:"enabled,omitempty"`
+	URL                string `json:"url,omitempty"`
+	SecretName         string `json:"secretName,omitempty"`
+	TokenKey           string `json:"tokenKey,omitempty"`
+	InsecureSkipVerify bool   `json:"insecureSkipVerify,omitempty"`
+}
+
+// LoadInjectorConfig reads the injector configuration from the YAML file at
+// @path and applies the defaults
+func LoadInjectorConfig(path string) (*InjectorConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := &InjectorConfig{}
+	if err := yaml.UnmarshalStrict(data, cfg); err != nil {
+		return nil, fmt.Errorf("parse injector config %s: %w", path, err)
+	}
+	if cfg.Image == "" {
+		return nil, fmt.Errorf("injector config %s: image is required", path)
+	}
+	if cfg.ImagePullPolicy == "" {
+		cfg.ImagePullPolicy = corev1.PullIfNotPresent
+	}
+	if cfg.ContainerName == "" {
+		cfg.ContainerName = "integrity"
+	}
+	if cfg.ServiceAccountName == "" {
+		cfg.ServiceAccountName = "integrity-monitor"
+	}
+	if cfg.MinIO.Enabled {
+		if cfg.MinIO.SecretName == "" {
+			cfg.MinIO.SecretName = "minio"
+		}
+		if cfg.MinIO.UserKey == "" {
+			cfg.MinIO.UserKey = "root-user"
+		}
+		if cfg.MinIO.PasswordKey == "" {
+			cfg.MinIO.PasswordKey = "root-password"
+		}
+	}
+	if cfg.Splunk.Enabled {
+		if cfg.Splunk.SecretName == "" {
+			return nil, fmt.Errorf("injector config %s: splunk.secretName is required", path)
+		}
+		if cfg.Splunk.TokenKey == "" {
+			cfg.Splunk.TokenKey = "token"
+		}
+	}
+	return cfg, nil
+}
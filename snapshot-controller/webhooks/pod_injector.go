@@ -0,0 +1,285 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhooks
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+const (
+	// LabelInject requests the injection of the integrity monitor sidecar into
+	// the pod if it is "true". The injector webhook is called only for the pods
+	// with the label outside of the controller namespace, see
+	// config/default/webhook_selector_patch.yaml.
+	LabelInject = "integrity-monitor.scnsoft.com/inject"
+	// annotationMonitoringPaths is prefixed with the process name, e.g.
+	// nginx.integrity-monitor.scnsoft.com/monitoring-paths: usr/bin,etc/nginx
+	annotationMonitoringPaths = "integrity-monitor.scnsoft.com/monitoring-paths"
+
+	// MutatePodPath is the path of the sidecar injector webhook
+	MutatePodPath = "/mutate-v1-pod"
+)
+
+//+kubebuilder:webhook:path=/mutate-v1-pod,mutating=true,failurePolicy=ignore,sideEffects=None,groups="",resources=pods,verbs=create,versions=v1,name=mpod.integrity.snapshot,admissionReviewVersions=v1
+// The namespace and the object selectors are not supported by the markers,
+// they are patched in config/default/webhook_selector_patch.yaml.
+
+// PodInjector injects the integrity monitor sidecar into the pods labeled
+// with LabelInject
+type PodInjector struct {
+	Config  *InjectorConfig
+	Log     logr.Logger
+	decoder *admission.Decoder
+}
+
+// SetupWithManager registers the injector webhook in the webhook server of
+// the Manager.
+func (p *PodInjector) SetupWithManager(mgr ctrl.Manager) error {
+	decoder, err := admission.NewDecoder(mgr.GetScheme())
+	if err != nil {
+		return err
+	}
+	p.decoder = decoder
+	mgr.GetWebhookServer().Register(MutatePodPath, &webhook.Admission{Handler: p})
+	return nil
+}
+
+// Handle adds the sidecar container to the pod of the admission request
+func (p *PodInjector) Handle(ctx context.Context, req admission.Request) admission.Response {
+	pod := &corev1.Pod{}
+	if err := p.decoder.Decode(req, pod); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+
+	injected, err := injectSidecar(pod, p.Config)
+	if err != nil {
+		p.Log.Info("sidecar injection rejected", "namespace", req.Namespace, "pod", podName(pod), "reason", err.Error())
+		return admission.Denied(err.Error())
+	}
+	if !injected {
+		return admission.Allowed("")
+	}
+
+	marshaled, err := json.Marshal(pod)
+	if err != nil {
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+	p.Log.V(1).Info("sidecar injected", "namespace", req.Namespace, "pod", podName(pod))
+	return admission.PatchResponseFromRaw(req.Object.Raw, marshaled)
+}
+
+// ..adds the sidecar to the @pod if it is requested by the label and is not
+// there yet, returns true if the @pod has been changed
+func injectSidecar(pod *corev1.Pod, cfg *InjectorConfig) (bool, error) {
+	if pod.Labels[LabelInject] != "true" {
+		return false, nil
+	}
+	for _, c := range pod.Spec.Containers {
+		if c.Name == cfg.ContainerName {
+			return false, nil
+		}
+	}
+
+	paths := monitoringPaths(pod.Annotations)
+	if len(paths) == 0 {
+		return false, fmt.Errorf("%s is set but no <process>.%s annotation is found", LabelInject, annotationMonitoringPaths)
+	}
+	// the sidecar needs the permissions of the monitor service account, a pod
+	// running under any other account would fail to patch, delete or report
+	switch pod.Spec.ServiceAccountName {
+	case "", "default", cfg.ServiceAccountName:
+	default:
+		return false, fmt.Errorf("service account %s is not allowed, the monitored pod must run under %s",
+			pod.Spec.ServiceAccountName, cfg.ServiceAccountName)
+	}
+
+	procs := make([]string, 0, len(paths))
+	for proc := range paths {
+		procs = append(procs, proc)
+	}
+	sort.Strings(procs)
+
+	options := make([]string, len(procs))
+	images := make([]string, len(procs))
+	for i, proc := range procs {
+		image, err := processImage(pod, proc)
+		if err != nil {
+			return false, err
+		}
+		options[i] = proc + "=" + paths[proc]
+		images[i] = "--process-image=" + proc + "=" + image
+	}
+	args := append([]string{"--monitoring-options=" + strings.Join(options, " ")}, images...)
+	args = append(args, alertArgs(cfg)...)
+	args = append(args, cfg.Args...)
+
+	shareProcessNamespace := true
+	pod.Spec.ShareProcessNamespace = &shareProcessNamespace
+	pod.Spec.ServiceAccountName = cfg.ServiceAccountName
+	pod.Spec.DeprecatedServiceAccount = cfg.ServiceAccountName
+	pod.Spec.Containers = append(pod.Spec.Containers, corev1.Container{
+		Name:            cfg.ContainerName,
+		Image:           cfg.Image,
+		ImagePullPolicy: cfg.ImagePullPolicy,
+		Args:            args,
+		Env:             sidecarEnv(pod, cfg),
+		Resources:       cfg.Resources,
+		LivenessProbe: &corev1.Probe{
+			ProbeHandler: corev1.ProbeHandler{
+				Exec: &corev1.ExecAction{
+					Command: []string{"/bin/sh", "-c", "[ -f /tmp/integrity-monitor ] && pidof integritySum"},
+				},
+			},
+			InitialDelaySeconds: 10,
+			PeriodSeconds:       5,
+		},
+		SecurityContext: &corev1.SecurityContext{
+			Capabilities: &corev1.Capabilities{
+				Add: []corev1.Capability{"SYS_PTRACE"},
+			},
+		},
+	})
+	return true, nil
+}
+
+// ..returns the monitoring paths of every process from the pod @annotations
+func monitoringPaths(annotations map[string]string) map[string]string {
+	paths := make(map[string]string)
+	for k, v := range annotations {
+		proc := strings.TrimSuffix(k, "."+annotationMonitoringPaths)
+		if proc == k || proc == "" || strings.TrimSpace(v) == "" {
+			continue
+		}
+		paths[proc] = strings.ReplaceAll(v, " ", "")
+	}
+	return paths
+}
+
+// ..returns the image of the container named after the @proc process, or of
+// the only container of the @pod
+func processImage(pod *corev1.Pod, proc string) (string, error) {
+	for _, c := range pod.Spec.Containers {
+		if c.Name == proc {
+			return c.Image, nil
+		}
+	}
+	if len(pod.Spec.Containers) == 1 {
+		return pod.Spec.Containers[0].Image, nil
+	}
+	return "", fmt.Errorf("unable to derive the image of the process %s: no container named %s", proc, proc)
+}
+
+// ..returns the sidecar arguments of the MinIO and the alert settings
+func alertArgs(cfg *InjectorConfig) []string {
+	var args []string
+	if cfg.MinIO.Enabled {
+		args = append(args, "--minio-enabled=true", "--minio-host="+cfg.MinIO.Host)
+	}
+	if cfg.Syslog.Enabled {
+		args = append(args,
+			"--syslog-enabled=true",
+			"--syslog-host="+cfg.Syslog.Host,
+			"--syslog-port="+cfg.Syslog.Port,
+			"--syslog-proto="+cfg.Syslog.Proto,
+		)
+	}
+	if cfg.Splunk.Enabled {
+		args = append(args,
+			"--splunk-enabled=true",
+			"--splunk-url="+cfg.Splunk.URL,
+			fmt.Sprintf("--splunk-insecure-skip-verify=%t", cfg.Splunk.InsecureSkipVerify),
+		)
+	}
+	return args
+}
+
+// ..returns the environment of the sidecar: the MinIO credentials, the Splunk
+// token and the pod data used by the monitor
+func sidecarEnv(pod *corev1.Pod, cfg *InjectorConfig) []corev1.EnvVar {
+	var env []corev1.EnvVar
+	if cfg.MinIO.Enabled {
+		env = append(env,
+			secretEnv("MINIO_SERVER_USER", cfg.MinIO.SecretName, cfg.MinIO.UserKey),
+			secretEnv("MINIO_SERVER_PASSWORD", cfg.MinIO.SecretName, cfg.MinIO.PasswordKey),
+		)
+	}
+	if cfg.Splunk.Enabled {
+		env = append(env, secretEnv("SPLUNK_TOKEN", cfg.Splunk.SecretName, cfg.Splunk.TokenKey))
+	}
+	return append(env,
+		fieldEnv("POD_NAME", "metadata.name"),
+		fieldEnv("POD_NAMESPACE", "metadata.namespace"),
+		corev1.EnvVar{Name: "DEPLOYMENT_TYPE", Value: deploymentType(pod)},
+	)
+}
+
+func secretEnv(name, secret, key string) corev1.EnvVar {
+	return corev1.EnvVar{
+		Name: name,
+		ValueFrom: &corev1.EnvVarSource{
+			SecretKeyRef: &corev1.SecretKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: secret},
+				Key:                  key,
+			},
+		},
+	}
+}
+
+func fieldEnv(name, path string) corev1.EnvVar {
+	return corev1.EnvVar{
+		Name: name,
+		ValueFrom: &corev1.EnvVarSource{
+			FieldRef: &corev1.ObjectFieldSelector{FieldPath: path},
+		},
+	}
+}
+
+// ..returns the kind of the @pod workload, the pods of a ReplicaSet are
+// considered as the deployment ones
+func deploymentType(pod *corev1.Pod) string {
+	for _, ref := range pod.OwnerReferences {
+		if ref.Controller != nil && *ref.Controller {
+			if ref.Kind == "ReplicaSet" {
+				return "deployment"
+			}
+			return strings.ToLower(ref.Kind)
+		}
+	}
+	return "pod"
+}
+
+// ..returns the name of the @pod, the name is not set yet for the pods
+// created by the controllers
+func podName(pod *corev1.Pod) string {
+	if pod.Name != "" {
+		return pod.Name
+	}
+	return pod.GenerateName
+}
+
+var _ admission.Handler = &PodInjector{}
@@ -0,0 +1,175 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhooks
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestInjectSidecar(t *testing.T) {
+	g := NewWithT(t)
+
+	cfg := &InjectorConfig{
+		Image:              "integrity:latest",
+		ImagePullPolicy:    corev1.PullIfNotPresent,
+		ContainerName:      "integrity",
+		ServiceAccountName: "integrity-monitor",
+		Args:               []string{"--verbose=info"},
+		MinIO:              MinIOConfig{Enabled: true, Host: "minio:9000", SecretName: "minio", UserKey: "u", PasswordKey: "p"},
+		Syslog:             SyslogConfig{Enabled: true, Host: "rsyslog", Port: "514", Proto: "tcp"},
+		Splunk:             SplunkConfig{Enabled: true, URL: "https://splunk:8088", SecretName: "splunk", TokenKey: "hec"},
+	}
+	controller := true
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Labels: map[string]string{LabelInject: "true"},
+			Annotations: map[string]string{
+				"nginx.integrity-monitor.scnsoft.com/monitoring-paths": "usr/bin, etc/nginx",
+				"redis.integrity-monitor.scnsoft.com/monitoring-paths": "usr/local/bin",
+			},
+			OwnerReferences: []metav1.OwnerReference{{Kind: "ReplicaSet", Name: "app-6d4cf56db6", Controller: &controller}},
+		},
+		Spec: corev1.PodSpec{Containers: []corev1.Container{
+			{Name: "nginx", Image: "nginx:1.25"},
+			{Name: "redis", Image: "redis:7.0"},
+		}},
+	}
+
+	injected, err := injectSidecar(pod, cfg)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(injected).To(BeTrue())
+	g.Expect(*pod.Spec.ShareProcessNamespace).To(BeTrue())
+	g.Expect(pod.Spec.ServiceAccountName).To(Equal("integrity-monitor"))
+	g.Expect(pod.Spec.Containers).To(HaveLen(3))
+
+	sidecar := pod.Spec.Containers[2]
+	g.Expect(sidecar.Name).To(Equal("integrity"))
+	g.Expect(sidecar.Image).To(Equal("integrity:latest"))
+	g.Expect(sidecar.Args).To(Equal([]string{
+		"--monitoring-options=nginx=usr/bin,etc/nginx redis=usr/local/bin",
+		"--process-image=nginx=nginx:1.25",
+		"--process-image=redis=redis:7.0",
+		"--minio-enabled=true",
+		"--minio-host=minio:9000",
+		"--syslog-enabled=true",
+		"--syslog-host=rsyslog",
+		"--syslog-port=514",
+		"--syslog-proto=tcp",
+		"--splunk-enabled=true",
+		"--splunk-url=https://splunk:8088",
+		"--splunk-insecure-skip-verify=false",
+		"--verbose=info",
+	}))
+	g.Expect(sidecar.Env).To(HaveLen(6))
+	g.Expect(sidecar.Env[0].Name).To(Equal("MINIO_SERVER_USER"))
+	g.Expect(sidecar.Env[0].ValueFrom.SecretKeyRef.Name).To(Equal("minio"))
+	g.Expect(sidecar.Env[0].ValueFrom.SecretKeyRef.Key).To(Equal("u"))
+	g.Expect(sidecar.Env[2]).To(Equal(secretEnv("SPLUNK_TOKEN", "splunk", "hec")))
+	g.Expect(sidecar.Env[5]).To(Equal(corev1.EnvVar{Name: "DEPLOYMENT_TYPE", Value: "deployment"}))
+	g.Expect(sidecar.SecurityContext.Capabilities.Add).To(ConsistOf(corev1.Capability("SYS_PTRACE")))
+
+	// the sidecar is already there
+	injected, err = injectSidecar(pod, cfg)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(injected).To(BeFalse())
+	g.Expect(pod.Spec.Containers).To(HaveLen(3))
+}
+
+func TestInjectSidecarSkipped(t *testing.T) {
+	g := NewWithT(t)
+	cfg := &InjectorConfig{Image: "integrity:latest", ContainerName: "integrity", ServiceAccountName: "integrity-monitor"}
+
+	pod := &corev1.Pod{Spec: corev1.PodSpec{ServiceAccountName: "default", Containers: []corev1.Container{{Name: "app", Image: "app:1"}}}}
+	injected, err := injectSidecar(pod, cfg)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(injected).To(BeFalse())
+	g.Expect(pod.Spec.ShareProcessNamespace).To(BeNil())
+
+	// the only container is taken for the process image
+	pod.Labels = map[string]string{LabelInject: "true"}
+	pod.Annotations = map[string]string{"nginx.integrity-monitor.scnsoft.com/monitoring-paths": "usr/bin"}
+	injected, err = injectSidecar(pod, cfg)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(injected).To(BeTrue())
+	g.Expect(pod.Spec.Containers[1].Args).To(ContainElement("--process-image=nginx=app:1"))
+	g.Expect(pod.Spec.ServiceAccountName).To(Equal("integrity-monitor"))
+}
+
+func TestInjectSidecarRejected(t *testing.T) {
+	g := NewWithT(t)
+	cfg := &InjectorConfig{Image: "integrity:latest", ContainerName: "integrity", ServiceAccountName: "integrity-monitor"}
+
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{LabelInject: "true"}},
+		Spec: corev1.PodSpec{Containers: []corev1.Container{
+			{Name: "app", Image: "app:1"},
+			{Name: "proxy", Image: "envoy:1"},
+		}},
+	}
+	_, err := injectSidecar(pod, cfg)
+	g.Expect(err).To(MatchError(ContainSubstring("no <process>.integrity-monitor.scnsoft.com/monitoring-paths")))
+
+	pod.Annotations = map[string]string{"nginx.integrity-monitor.scnsoft.com/monitoring-paths": "usr/bin"}
+	_, err = injectSidecar(pod, cfg)
+	g.Expect(err).To(MatchError(ContainSubstring("no container named nginx")))
+	g.Expect(pod.Spec.Containers).To(HaveLen(2))
+
+	// the sidecar would lack the permissions under another service account
+	pod.Spec.Containers[0].Name = "nginx"
+	pod.Spec.ServiceAccountName = "app"
+	_, err = injectSidecar(pod, cfg)
+	g.Expect(err).To(MatchError(ContainSubstring("service account app is not allowed")))
+	g.Expect(pod.Spec.Containers).To(HaveLen(2))
+}
+
+func TestLoadInjectorConfig(t *testing.T) {
+	g := NewWithT(t)
+	path := filepath.Join(t.TempDir(), "config.yaml")
+
+	g.Expect(os.WriteFile(path, []byte("image: integrity:1.0\nminio:\n  enabled: true\n  host: minio:9000\n"), 0o600)).To(Succeed())
+	cfg, err := LoadInjectorConfig(path)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(cfg.Image).To(Equal("integrity:1.0"))
+	g.Expect(cfg.ContainerName).To(Equal("integrity"))
+	g.Expect(cfg.ServiceAccountName).To(Equal("integrity-monitor"))
+	g.Expect(cfg.ImagePullPolicy).To(Equal(corev1.PullIfNotPresent))
+	g.Expect(cfg.MinIO).To(Equal(MinIOConfig{
+		Enabled: true, Host: "minio:9000", SecretName: "minio", UserKey: "root-user", PasswordKey: "root-password",
+	}))
+
+	g.Expect(os.WriteFile(path, []byte("image: integrity:1.0\nimgae: typo\n"), 0o600)).To(Succeed())
+	_, err = LoadInjectorConfig(path)
+	g.Expect(err).To(HaveOccurred())
+
+	g.Expect(os.WriteFile(path, []byte("image: integrity:1.0\nsplunk:\n  enabled: true\n  token: plain\n"), 0o600)).To(Succeed())
+	_, err = LoadInjectorConfig(path)
+	g.Expect(err).To(HaveOccurred())
+
+	g.Expect(os.WriteFile(path, []byte("image: integrity:1.0\nsplunk:\n  enabled: true\n"), 0o600)).To(Succeed())
+	_, err = LoadInjectorConfig(path)
+	g.Expect(err).To(MatchError(ContainSubstring("splunk.secretName is required")))
+
+	g.Expect(os.WriteFile(path, []byte("containerName: integrity\n"), 0o600)).To(Succeed())
+	_, err = LoadInjectorConfig(path)
+	g.Expect(err).To(MatchError(ContainSubstring("image is required")))
+}
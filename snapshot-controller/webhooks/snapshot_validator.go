@@ -0,0 +1,207 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhooks
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	mstorage "github.com/ScienceSoft-Inc/integrity-sum/pkg/minio"
+	integrityv1 "github.com/ScienceSoft-Inc/integrity-sum/snapshot-controller/api/v1"
+)
+
+const (
+	// ValidationOff disables the snapshot validation of the pods
+	ValidationOff = "off"
+	// ValidationWarn admits the pods without a snapshot with a warning
+	ValidationWarn = "warn"
+	// ValidationDeny rejects the pods without a snapshot
+	ValidationDeny = "deny"
+
+	// ValidatePodPath is the path of the snapshot validation webhook
+	ValidatePodPath = "/validate-v1-pod"
+
+	// defaultAlgorithm is the default --algorithm of the monitor
+	defaultAlgorithm = "SHA256"
+)
+
+//+kubebuilder:webhook:path=/validate-v1-pod,mutating=false,failurePolicy=ignore,sideEffects=None,groups="",resources=pods,verbs=create,versions=v1,name=vpod.integrity.snapshot,admissionReviewVersions=v1
+// The namespace selector is patched in config/default/webhook_selector_patch.yaml.
+
+// SnapshotValidator checks that the processes of the monitored pods have the
+// uploaded snapshots, otherwise the monitor fails on every scan
+type SnapshotValidator struct {
+	Client  client.Reader
+	Mode    string
+	Log     logr.Logger
+	decoder *admission.Decoder
+}
+
+// snapshotRef is the snapshot expected by a process of the monitored pod
+type snapshotRef struct {
+	Process   string
+	Image     string
+	Algorithm string
+}
+
+// SetupWithManager registers the validation webhook in the webhook server of
+// the Manager.
+func (v *SnapshotValidator) SetupWithManager(mgr ctrl.Manager) error {
+	if v.Mode != ValidationWarn && v.Mode != ValidationDeny {
+		return fmt.Errorf("unknown snapshot validation mode %q", v.Mode)
+	}
+	decoder, err := admission.NewDecoder(mgr.GetScheme())
+	if err != nil {
+		return err
+	}
+	v.decoder = decoder
+	mgr.GetWebhookServer().Register(ValidatePodPath, &webhook.Admission{Handler: v})
+	return nil
+}
+
+// Handle rejects or warns about the pod of the admission request if any of its
+// monitored processes has no uploaded snapshot
+func (v *SnapshotValidator) Handle(ctx context.Context, req admission.Request) admission.Response {
+	pod := &corev1.Pod{}
+	if err := v.decoder.Decode(req, pod); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+
+	refs, learn := expectedSnapshots(pod)
+	if len(refs) == 0 || learn {
+		return admission.Allowed("")
+	}
+
+	var snapshots integrityv1.SnapshotList
+	if err := v.Client.List(ctx, &snapshots, client.InNamespace(req.Namespace)); err != nil {
+		// admit the pod as if the webhook is unavailable, see failurePolicy
+		v.Log.Error(err, "unable to list snapshots", "namespace", req.Namespace)
+		return admission.Allowed("").WithWarnings("unable to check the snapshots: " + err.Error())
+	}
+
+	missing := missingSnapshots(req.Namespace, refs, snapshots.Items)
+	if len(missing) == 0 {
+		return admission.Allowed("")
+	}
+	msg := "no uploaded snapshot for " + strings.Join(missing, ", ")
+	v.Log.Info("pod without snapshot", "namespace", req.Namespace, "pod", podName(pod), "mode", v.Mode, "missing", missing)
+	if v.Mode == ValidationWarn {
+		return admission.Allowed("").WithWarnings(msg)
+	}
+	return admission.Denied(msg)
+}
+
+// ..returns the snapshots expected by the monitored processes of the @pod and
+// whether the missing snapshots are learned by the monitor. The sidecar
+// arguments are used if the sidecar is in the pod, otherwise the processes are
+// taken from the monitoring-paths annotations of the pods labeled to be
+// injected.
+func expectedSnapshots(pod *corev1.Pod) ([]snapshotRef, bool) {
+	for _, c := range pod.Spec.Containers {
+		if images, alg, learn, ok := sidecarArgs(c.Args); ok {
+			return snapshotRefs(images, alg), learn
+		}
+	}
+
+	if pod.Labels[LabelInject] != "true" {
+		return nil, false
+	}
+	images := make(map[string]string)
+	for proc := range monitoringPaths(pod.Annotations) {
+		// the image error is reported by the injector
+		if image, err := processImage(pod, proc); err == nil {
+			images[proc] = image
+		}
+	}
+	return snapshotRefs(images, defaultAlgorithm), false
+}
+
+// ..parses the process images, the algorithm and the baseline learning mode
+// from the monitor @args, ok is false if the @args are not the monitor ones
+func sidecarArgs(args []string) (images map[string]string, alg string, learn bool, ok bool) {
+	images = make(map[string]string)
+	alg = defaultAlgorithm
+	for _, arg := range args {
+		name, value, found := strings.Cut(arg, "=")
+		if !found {
+			continue
+		}
+		switch name {
+		case "--monitoring-options":
+			ok = true
+		case "--process-image":
+			for _, pair := range strings.Split(value, ",") {
+				if proc, image, found := strings.Cut(pair, "="); found {
+					images[proc] = image
+				}
+			}
+		case "--algorithm":
+			alg = value
+		case "--learn-baseline":
+			learn = value != "" && value != "off"
+		}
+	}
+	return images, alg, learn, ok
+}
+
+func snapshotRefs(images map[string]string, alg string) []snapshotRef {
+	refs := make([]snapshotRef, 0, len(images))
+	for proc, image := range images {
+		refs = append(refs, snapshotRef{Process: proc, Image: image, Algorithm: alg})
+	}
+	sort.Slice(refs, func(i, j int) bool { return refs[i].Process < refs[j].Process })
+	return refs
+}
+
+// ..returns the descriptions of the @refs without the uploaded snapshot among
+// the @snapshots of the @namespace. The snapshots are matched by the MinIO
+// object name the monitor loads the hashes from.
+func missingSnapshots(namespace string, refs []snapshotRef, snapshots []integrityv1.Snapshot) []string {
+	uploaded := make(map[string]bool)
+	for i := range snapshots {
+		s := &snapshots[i]
+		if !s.Status.IsUploaded || s.DeletionTimestamp != nil || !strings.Contains(s.Spec.Image, ":") {
+			continue
+		}
+		uploaded[mstorage.BuildObjectName(s.Namespace, s.Spec.Image, s.Spec.Algorithm)] = true
+	}
+
+	var missing []string
+	for _, ref := range refs {
+		if !strings.Contains(ref.Image, ":") {
+			missing = append(missing, fmt.Sprintf("process %s: image %s has no tag", ref.Process, ref.Image))
+			continue
+		}
+		if !uploaded[mstorage.BuildObjectName(namespace, ref.Image, ref.Algorithm)] {
+			missing = append(missing, fmt.Sprintf("process %s: image %s, algorithm %s",
+				ref.Process, ref.Image, strings.ToLower(ref.Algorithm)))
+		}
+	}
+	return missing
+}
+
+var _ admission.Handler = &SnapshotValidator{}
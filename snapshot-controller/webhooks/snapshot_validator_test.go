@@ -0,0 +1,151 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhooks
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/go-logr/logr"
+	. "github.com/onsi/gomega"
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	integrityv1 "github.com/ScienceSoft-Inc/integrity-sum/snapshot-controller/api/v1"
+)
+
+func TestExpectedSnapshots(t *testing.T) {
+	g := NewWithT(t)
+
+	// the sidecar arguments
+	pod := &corev1.Pod{Spec: corev1.PodSpec{Containers: []corev1.Container{
+		{Name: "nginx", Image: "nginx:1.25"},
+		{Name: "integrity", Args: []string{
+			"--monitoring-options=nginx=usr/bin redis=usr/local/bin",
+			"--process-image=nginx=nginx:1.25,redis=redis:7.0",
+			"--algorithm=MD5",
+		}},
+	}}}
+	refs, learn := expectedSnapshots(pod)
+	g.Expect(learn).To(BeFalse())
+	g.Expect(refs).To(Equal([]snapshotRef{
+		{Process: "nginx", Image: "nginx:1.25", Algorithm: "MD5"},
+		{Process: "redis", Image: "redis:7.0", Algorithm: "MD5"},
+	}))
+
+	pod.Spec.Containers[1].Args = append(pod.Spec.Containers[1].Args, "--learn-baseline=minio")
+	_, learn = expectedSnapshots(pod)
+	g.Expect(learn).To(BeTrue())
+
+	// the annotations of the pod to be injected
+	pod = &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Labels:      map[string]string{LabelInject: "true"},
+			Annotations: map[string]string{"nginx.integrity-monitor.scnsoft.com/monitoring-paths": "usr/bin"},
+		},
+		Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "nginx", Image: "nginx:1.25"}}},
+	}
+	refs, _ = expectedSnapshots(pod)
+	g.Expect(refs).To(Equal([]snapshotRef{{Process: "nginx", Image: "nginx:1.25", Algorithm: "SHA256"}}))
+
+	// not monitored
+	delete(pod.Labels, LabelInject)
+	refs, _ = expectedSnapshots(pod)
+	g.Expect(refs).To(BeEmpty())
+}
+
+func TestMissingSnapshots(t *testing.T) {
+	g := NewWithT(t)
+
+	snapshot := func(ns, image, alg string, uploaded bool) integrityv1.Snapshot {
+		return integrityv1.Snapshot{
+			ObjectMeta: metav1.ObjectMeta{Name: "s", Namespace: ns},
+			Spec:       integrityv1.SnapshotSpec{Image: image, Algorithm: alg},
+			Status:     integrityv1.SnapshotStatus{IsUploaded: uploaded},
+		}
+	}
+	snapshots := []integrityv1.Snapshot{
+		snapshot("default", "nginx:1.25", "sha256", true),
+		snapshot("default", "redis:7.0", "sha256", false),
+		snapshot("default", "app", "sha256", true),
+	}
+
+	missing := missingSnapshots("default", []snapshotRef{
+		{Process: "nginx", Image: "nginx:1.25", Algorithm: "SHA256"},
+		{Process: "redis", Image: "redis:7.0", Algorithm: "SHA256"},
+		{Process: "old", Image: "nginx:1.24", Algorithm: "SHA256"},
+		{Process: "md5", Image: "nginx:1.25", Algorithm: "MD5"},
+		{Process: "app", Image: "app", Algorithm: "SHA256"},
+	}, snapshots)
+	g.Expect(missing).To(Equal([]string{
+		"process redis: image redis:7.0, algorithm sha256",
+		"process old: image nginx:1.24, algorithm sha256",
+		"process md5: image nginx:1.25, algorithm md5",
+		"process app: image app has no tag",
+	}))
+}
+
+func TestSnapshotValidatorHandle(t *testing.T) {
+	g := NewWithT(t)
+
+	scheme := runtime.NewScheme()
+	g.Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+	g.Expect(integrityv1.AddToScheme(scheme)).To(Succeed())
+	decoder, err := admission.NewDecoder(scheme)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(&integrityv1.Snapshot{
+		ObjectMeta: metav1.ObjectMeta{Name: "nginx", Namespace: "default"},
+		Spec:       integrityv1.SnapshotSpec{Image: "nginx:1.25", Algorithm: "sha256"},
+		Status:     integrityv1.SnapshotStatus{IsUploaded: true},
+	}).Build()
+
+	request := func(image string) admission.Request {
+		raw, err := json.Marshal(&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Labels:      map[string]string{LabelInject: "true"},
+				Annotations: map[string]string{"nginx.integrity-monitor.scnsoft.com/monitoring-paths": "usr/bin"},
+			},
+			Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "nginx", Image: image}}},
+		})
+		g.Expect(err).NotTo(HaveOccurred())
+		return admission.Request{AdmissionRequest: admissionv1.AdmissionRequest{
+			Namespace: "default",
+			Operation: admissionv1.Create,
+			Object:    runtime.RawExtension{Raw: raw},
+		}}
+	}
+
+	v := &SnapshotValidator{Client: c, Mode: ValidationDeny, Log: logr.Discard(), decoder: decoder}
+	resp := v.Handle(context.Background(), request("nginx:1.25"))
+	g.Expect(resp.Allowed).To(BeTrue())
+
+	resp = v.Handle(context.Background(), request("nginx:1.26"))
+	g.Expect(resp.Allowed).To(BeFalse())
+	g.Expect(string(resp.Result.Reason)).To(Equal("no uploaded snapshot for process nginx: image nginx:1.26, algorithm sha256"))
+
+	v.Mode = ValidationWarn
+	resp = v.Handle(context.Background(), request("nginx:1.26"))
+	g.Expect(resp.Allowed).To(BeTrue())
+	g.Expect(resp.Warnings).To(ConsistOf("no uploaded snapshot for process nginx: image nginx:1.26, algorithm sha256"))
+}